@@ -0,0 +1,48 @@
+package auth
+
+import "time"
+
+//roles, each role has access to everything allowed for previous ones
+const (
+	ViewerRole   = "viewer"   //schema info, statuses, reports
+	OperatorRole = "operator" //running jobs
+	AdminRole    = "admin"    //managing policies and users
+)
+
+var roleLevels = map[string]int{
+	ViewerRole:   1,
+	OperatorRole: 2,
+	AdminRole:    3,
+}
+
+type User struct {
+	Login        string `yaml:"login" json:"login"`
+	PasswordHash string `yaml:"passwordHash" json:"passwordHash"` //bcrypt
+	Role         string `yaml:"role" json:"role"`
+	//admin generated on the first start, isn't accepted if users are set in config or environment
+	Generated bool `yaml:"-" json:"generated,omitempty"`
+}
+
+type Token struct {
+	Id      string
+	Login   string
+	Role    string
+	Created time.Time
+	Expires time.Time
+	//sha256 of token, token itself is returned only once on creation
+	Hash string `json:",omitempty"`
+}
+
+type Principal struct {
+	Login string
+	Role  string
+}
+
+func IsKnownRole(role string) bool {
+	_, exists := roleLevels[role]
+	return exists
+}
+
+func HasRole(actual, required string) bool {
+	return roleLevels[actual] >= roleLevels[required]
+}
@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"obfuscator/config"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	tokensFileName = "tokens.json"
+	tokenBytes     = 32
+	tokenIdBytes   = 4
+)
+
+var tokensMutex sync.Mutex
+
+//returns token value which isn't stored anywhere
+func CreateToken(principal Principal, role string, ttl time.Duration) (string, Token, error) {
+	if role == "" {
+		role = principal.Role
+	}
+	if !IsKnownRole(role) {
+		return "", Token{}, fmt.Errorf("unknown role %v", role)
+	}
+	if !HasRole(principal.Role, role) {
+		return "", Token{}, fmt.Errorf("token can't have role higher than user's one")
+	}
+	if ttl <= 0 {
+		ttl = time.Duration(config.GetConfig().Auth.TokenTtlHours) * time.Hour
+	}
+
+	value, err := randomHex(tokenBytes)
+	if err != nil {
+		return "", Token{}, err
+	}
+	id, err := randomHex(tokenIdBytes)
+	if err != nil {
+		return "", Token{}, err
+	}
+	now := time.Now().UTC()
+	token := Token{
+		Id:      id,
+		Login:   principal.Login,
+		Role:    role,
+		Created: now,
+		Expires: now.Add(ttl),
+		Hash:    hashToken(value),
+	}
+
+	tokensMutex.Lock()
+	defer tokensMutex.Unlock()
+	tokens, err := readTokens()
+	if err != nil {
+		return "", Token{}, err
+	}
+	tokens = append(removeExpired(tokens), token)
+	err = writeJsonFile(getTokensFilePath(), tokens)
+	if err != nil {
+		return "", Token{}, err
+	}
+	token.Hash = ""
+	return value, token, nil
+}
+
+//role of the token is lowered to the current role of the user
+func AuthenticateToken(value string) (Principal, bool) {
+	token, found := findToken(value)
+	if !found {
+		return Principal{}, false
+	}
+	user, exists := findUser(token.Login)
+	if !exists || !IsKnownRole(user.Role) {
+		return Principal{}, false
+	}
+	role := token.Role
+	if !HasRole(user.Role, role) {
+		role = user.Role
+	}
+	return Principal{Login: token.Login, Role: role}, true
+}
+
+func findToken(value string) (Token, bool) {
+	tokensMutex.Lock()
+	defer tokensMutex.Unlock()
+	tokens, err := readTokens()
+	if err != nil {
+		return Token{}, false
+	}
+	hash := hashToken(value)
+	now := time.Now()
+	for _, token := range tokens {
+		if subtle.ConstantTimeCompare([]byte(token.Hash), []byte(hash)) == 1 && now.Before(token.Expires) {
+			return token, true
+		}
+	}
+	return Token{}, false
+}
+
+//admin gets tokens of all users
+func ListTokens(principal Principal) ([]Token, error) {
+	tokensMutex.Lock()
+	defer tokensMutex.Unlock()
+	tokens, err := readTokens()
+	if err != nil {
+		return nil, err
+	}
+	result := []Token{}
+	for _, token := range removeExpired(tokens) {
+		if token.Login == principal.Login || principal.Role == AdminRole {
+			token.Hash = ""
+			result = append(result, token)
+		}
+	}
+	return result, nil
+}
+
+func RevokeToken(principal Principal, id string) error {
+	tokensMutex.Lock()
+	defer tokensMutex.Unlock()
+	tokens, err := readTokens()
+	if err != nil {
+		return err
+	}
+	var result []Token
+	for _, token := range tokens {
+		if token.Id == id && (token.Login == principal.Login || principal.Role == AdminRole) {
+			continue
+		}
+		result = append(result, token)
+	}
+	if len(result) == len(tokens) {
+		return fmt.Errorf("token %v doesn't exist", id)
+	}
+	return writeJsonFile(getTokensFilePath(), result)
+}
+
+func revokeUserTokens(login string) error {
+	tokensMutex.Lock()
+	defer tokensMutex.Unlock()
+	tokens, err := readTokens()
+	if err != nil {
+		return err
+	}
+	var result []Token
+	for _, token := range tokens {
+		if token.Login != login {
+			result = append(result, token)
+		}
+	}
+	return writeJsonFile(getTokensFilePath(), result)
+}
+
+func readTokens() ([]Token, error) {
+	data, err := os.ReadFile(getTokensFilePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tokens []Token
+	err = json.Unmarshal(data, &tokens)
+	return tokens, err
+}
+
+func removeExpired(tokens []Token) []Token {
+	var result []Token
+	now := time.Now()
+	for _, token := range tokens {
+		if now.Before(token.Expires) {
+			result = append(result, token)
+		}
+	}
+	return result
+}
+
+func getTokensFilePath() string {
+	return filepath.Join(config.GetConfig().Storage.Dir, tokensFileName)
+}
+
+func hashToken(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+func randomHex(size int) (string, error) {
+	buffer := make([]byte, size)
+	_, err := rand.Read(buffer)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer), nil
+}
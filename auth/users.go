@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"obfuscator/config"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	usersFileName        = "users.json"
+	adminPasswordFile    = "admin.password"
+	AdminLogin           = "admin"
+	adminPasswordHashEnv = "OBFUSCATOR_ADMIN_PASSWORD_HASH"
+)
+
+var usersMutex sync.Mutex
+
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+func AuthenticateUser(login, password string) (Principal, bool) {
+	users, err := getAllUsers()
+	if err != nil {
+		return Principal{}, false
+	}
+	for _, user := range users {
+		if user.Login == login && bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) == nil {
+			return Principal{Login: user.Login, Role: user.Role}, true
+		}
+	}
+	return Principal{}, false
+}
+
+//stores admin with generated password if no users are configured, so it happens only on the first start.
+//The password is written to a file readable only by owner, returns its path or empty string if the admin wasn't added
+func InitDefaultAdmin() (string, error) {
+	users, err := getAllUsers()
+	if err != nil || len(users) > 0 {
+		return "", err
+	}
+	password, err := randomHex(tokenBytes / 2)
+	if err != nil {
+		return "", err
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return "", err
+	}
+	usersMutex.Lock()
+	defer usersMutex.Unlock()
+	err = writeStoredUsers([]User{{Login: AdminLogin, PasswordHash: hash, Role: AdminRole, Generated: true}})
+	if err != nil {
+		return "", err
+	}
+	path := getAdminPasswordPath()
+	err = os.WriteFile(path, []byte(password+"\n"), 0600)
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+//returns user with current role, users can be removed or changed after tokens were created
+func findUser(login string) (User, bool) {
+	users, err := getAllUsers()
+	if err != nil {
+		return User{}, false
+	}
+	for _, user := range users {
+		if user.Login == login {
+			return user, true
+		}
+	}
+	return User{}, false
+}
+
+//users from config and environment can't be changed via API
+func ListUsers() ([]User, error) {
+	users, err := getAllUsers()
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		users[i].PasswordHash = ""
+	}
+	return users, nil
+}
+
+func SaveUser(login, password, role string) error {
+	if login == "" || password == "" {
+		return fmt.Errorf("login and password are required")
+	}
+	if !IsKnownRole(role) {
+		return fmt.Errorf("unknown role %v", role)
+	}
+	for _, user := range getStaticUsers() {
+		if user.Login == login {
+			return fmt.Errorf("user %v is set in config and can't be changed", login)
+		}
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	usersMutex.Lock()
+	defer usersMutex.Unlock()
+	users, err := readStoredUsers()
+	if err != nil {
+		return err
+	}
+	updated := false
+	for i := range users {
+		if users[i].Login == login {
+			users[i].PasswordHash = hash
+			users[i].Role = role
+			users[i].Generated = false
+			updated = true
+		}
+	}
+	if !updated {
+		users = append(users, User{Login: login, PasswordHash: hash, Role: role})
+	}
+	err = writeStoredUsers(users)
+	if err != nil {
+		return err
+	}
+	//generated password isn't valid anymore
+	if login == AdminLogin {
+		err = os.Remove(getAdminPasswordPath())
+		if os.IsNotExist(err) {
+			return nil
+		}
+	}
+	return err
+}
+
+func DeleteUser(login string) error {
+	usersMutex.Lock()
+	defer usersMutex.Unlock()
+	users, err := readStoredUsers()
+	if err != nil {
+		return err
+	}
+	var result []User
+	for _, user := range users {
+		if user.Login != login {
+			result = append(result, user)
+		}
+	}
+	if len(result) == len(users) {
+		return fmt.Errorf("user %v doesn't exist or is set in config", login)
+	}
+	err = writeStoredUsers(result)
+	if err != nil {
+		return err
+	}
+	return revokeUserTokens(login)
+}
+
+func getAllUsers() ([]User, error) {
+	usersMutex.Lock()
+	defer usersMutex.Unlock()
+	stored, err := readStoredUsers()
+	if err != nil {
+		return nil, err
+	}
+	users := getStaticUsers()
+	//bootstrap admin is replaced by configured users
+	bootstrap := len(users) == 0
+	for _, user := range stored {
+		if !user.Generated || bootstrap {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func getStaticUsers() []User {
+	var users []User
+	for _, user := range config.GetConfig().Auth.Users {
+		users = append(users, User{Login: user.Login, PasswordHash: user.PasswordHash, Role: user.Role})
+	}
+	if hash := os.Getenv(adminPasswordHashEnv); hash != "" {
+		users = append(users, User{Login: AdminLogin, PasswordHash: hash, Role: AdminRole})
+	}
+	return users
+}
+
+func readStoredUsers() ([]User, error) {
+	data, err := os.ReadFile(getUsersFilePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var users []User
+	err = json.Unmarshal(data, &users)
+	return users, err
+}
+
+func writeStoredUsers(users []User) error {
+	return writeJsonFile(getUsersFilePath(), users)
+}
+
+func getUsersFilePath() string {
+	return filepath.Join(config.GetConfig().Storage.Dir, usersFileName)
+}
+
+func getAdminPasswordPath() string {
+	return filepath.Join(config.GetConfig().Storage.Dir, adminPasswordFile)
+}
+
+func writeJsonFile(path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
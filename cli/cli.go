@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+	"obfuscator/auth"
+	"obfuscator/secrets"
+)
+
+const (
+	usage = `usage:
+  obfuscator                                         start http-server
+  obfuscator policy import <file> [author] [comment] save policy file as a new version
+  obfuscator policy check <file>                     check policy file format
+  obfuscator policy show <name> [version]            print policy
+  obfuscator policy history <name>                   print policy versions
+  obfuscator profile save <name> <user> <host> <schema>
+                                                     save connection profile, password is read from stdin
+                                                     or OBFUSCATOR_PROFILE_PASSWORD
+  obfuscator profile list                            print connection profiles
+  obfuscator profile delete <name>                   delete connection profile
+  obfuscator hash-password <password>                print bcrypt hash for config
+  obfuscator init-keys                               generate master and fpe key files which don't exist`
+)
+
+//args without program name
+func Run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(usage)
+	}
+	switch args[0] {
+	case "policy":
+		return runPolicy(args[1:])
+	case "profile":
+		return runProfile(args[1:])
+	case "hash-password":
+		if len(args) < 2 {
+			return fmt.Errorf(usage)
+		}
+		hash, err := auth.HashPassword(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Println(hash)
+		return nil
+	case "init-keys":
+		generated, err := secrets.InitKeys()
+		for _, path := range generated {
+			fmt.Println("Key was generated: " + path)
+		}
+		return err
+	default:
+		return fmt.Errorf("unknown command %v\n%v", args[0], usage)
+	}
+}
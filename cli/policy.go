@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"obfuscator/policy"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func runPolicy(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf(usage)
+	}
+	switch args[0] {
+	case "import":
+		return importPolicy(args[1], getArg(args, 2, os.Getenv("USER")), getArg(args, 3, ""))
+	case "check":
+		p, err := policy.ParseFile(args[1])
+		if err != nil {
+			return err
+		}
+		err = policy.Validate(p)
+		if err != nil {
+			return err
+		}
+		fmt.Println("OK")
+		return nil
+	case "show":
+		return showPolicy(args[1], getArg(args, 2, "0"))
+	case "history":
+		return showPolicyHistory(args[1])
+	default:
+		return fmt.Errorf("unknown policy command %v\n%v", args[0], usage)
+	}
+}
+
+func importPolicy(path, author, comment string) error {
+	p, err := policy.ParseFile(path)
+	if err != nil {
+		return err
+	}
+	info, err := policy.Save(p, author, comment)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Policy %v saved, version %v\n", p.Name, info.Version)
+	for _, change := range info.Changes {
+		fmt.Println("  " + change)
+	}
+	return nil
+}
+
+func showPolicy(name, versionStr string) error {
+	version, err := strconv.Atoi(versionStr)
+	if err != nil {
+		return err
+	}
+	p, _, err := policy.Load(name, version)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+func showPolicyHistory(name string) error {
+	history, err := policy.History(name)
+	if err != nil {
+		return err
+	}
+	for _, info := range history {
+		fmt.Printf("version %v by %v at %v %v\n", info.Version, info.Author, info.Created.Format("2006-01-02 15:04:05"), info.Comment)
+		fmt.Println("  " + strings.Join(info.Changes, "\n  "))
+	}
+	return nil
+}
+
+func getArg(args []string, i int, defaultValue string) string {
+	if len(args) > i {
+		return args[i]
+	}
+	return defaultValue
+}
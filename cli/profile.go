@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"obfuscator/obfuscating"
+	"obfuscator/profile"
+	"os"
+	"strings"
+)
+
+const (
+	profilePasswordEnv = "OBFUSCATOR_PROFILE_PASSWORD"
+)
+
+func runProfile(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf(usage)
+	}
+	switch args[0] {
+	case "save":
+		if len(args) < 5 {
+			return fmt.Errorf(usage)
+		}
+		password, err := readPassword()
+		if err != nil {
+			return err
+		}
+		err = profile.Save(args[1], obfuscating.ConnectionInfo{
+			User:     args[2],
+			Password: password,
+			Host:     args[3],
+			Schema:   args[4],
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Profile %v saved\n", args[1])
+		return nil
+	case "list":
+		profiles, err := profile.List()
+		if err != nil {
+			return err
+		}
+		for _, p := range profiles {
+			fmt.Printf("%v: %v@%v/%v\n", p.Profile, p.User, p.Host, p.Schema)
+		}
+		return nil
+	case "delete":
+		if len(args) < 2 {
+			return fmt.Errorf(usage)
+		}
+		return profile.Delete(args[1])
+	default:
+		return fmt.Errorf("unknown profile command %v\n%v", args[0], usage)
+	}
+}
+
+//password isn't taken from arguments not to be kept in shell history
+func readPassword() (string, error) {
+	if password := os.Getenv(profilePasswordEnv); password != "" {
+		return password, nil
+	}
+	fmt.Print("Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(password, "\r\n"), nil
+}
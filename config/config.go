@@ -17,6 +17,44 @@ type Config struct {
 	Obfuscator struct {
 		SliceSize         int   `yaml:"sliceSize"`
 		DispersionPercent int64 `yaml:"dispersionPercent"`
+		//MIME type -> path of file used by placeholder strategy instead of built-in placeholder
+		Placeholders map[string]string `yaml:"placeholders"`
+		//name -> path of CSV file with header used by tuple strategy, overrides built-in set of the same name
+		ReferenceSets map[string]string `yaml:"referenceSets"`
+		//max time of script strategy evaluation per value
+		ScriptTimeoutMs int `yaml:"scriptTimeoutMs"`
+		//max count of values kept per obfuscated UNIQUE column to avoid collisions, not limited if 0
+		MaxUniqueValues int `yaml:"maxUniqueValues"`
+	}
+	Detection struct {
+		SampleSize    int     `yaml:"sampleSize"`
+		MinConfidence float64 `yaml:"minConfidence"`
+	}
+	LeakScan struct {
+		//all values are scanned if 0
+		SampleSize     int `yaml:"sampleSize"`
+		MinValueLength int `yaml:"minValueLength"`
+	} `yaml:"leakScan"`
+	Auth struct {
+		Users []struct {
+			Login        string `yaml:"login"`
+			PasswordHash string `yaml:"passwordHash"` //bcrypt, see "obfuscator hash-password"
+			Role         string `yaml:"role"`
+		}
+		TokenTtlHours int `yaml:"tokenTtlHours"`
+	}
+	Secrets struct {
+		//used if OBFUSCATOR_MASTER_KEY isn't set, generated by "obfuscator init-keys"
+		MasterKeyFile string `yaml:"masterKeyFile"`
+		//used by fpe strategy if OBFUSCATOR_FPE_KEY isn't set, generated by "obfuscator init-keys"
+		FpeKeyFile string `yaml:"fpeKeyFile"`
+	}
+	Storage struct {
+		Dir string `yaml:"dir"`
+	}
+	Vault struct {
+		//encrypted by master key, opened only if vault strategy is used
+		File string `yaml:"file"`
 	}
 }
 
@@ -47,10 +85,19 @@ func readConfigFromFile() *Config {
 	return config
 }
 
+//config is searched in working directory and its parents, e.g. tests are run in package directories
 func getConfigPath() string {
 	wd, err := os.Getwd()
 	if err != nil {
 		panic(err)
 	}
-	return filepath.Join(wd, configFileName)
+	for dir := wd; ; dir = filepath.Dir(dir) {
+		path := filepath.Join(dir, configFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		if filepath.Dir(dir) == dir {
+			return filepath.Join(wd, configFileName)
+		}
+	}
 }
@@ -0,0 +1,188 @@
+package detection
+
+import (
+	"math"
+	"net"
+	"obfuscator/encoding"
+	"regexp"
+	"strings"
+)
+
+//kinds of personal data
+const (
+	NoneKind       = ""
+	EmailKind      = "email"
+	PhoneKind      = "phone"
+	IbanKind       = "iban"
+	CardKind       = "card"
+	NationalIdKind = "nationalId"
+	IpKind         = "ip"
+	NameKind       = "name"
+	AddressKind    = "address"
+	BirthDateKind  = "birthDate"
+	SecretKind     = "secret"
+)
+
+const (
+	nameWeight    = 0.6
+	commentWeight = 0.4
+	valuesWeight  = 0.9
+	//share of sampled values which must match to take values into account
+	minValuesMatchRatio = 0.5
+)
+
+type ColumnSample struct {
+	Name    string
+	Type    string
+	Comment string
+	Values  []string
+}
+
+type Suggestion struct {
+	Kind       string
+	Strategy   string
+	Confidence float64
+	Reasons    []string
+}
+
+type kindRule struct {
+	kind         string
+	namePattern  *regexp.Regexp
+	valueMatches func(value string) bool
+}
+
+//order matters: more specific kinds go first
+var kindRules = []kindRule{
+	{EmailKind, regexp.MustCompile(`(?i)e_?mail`), isEmail},
+	{IbanKind, regexp.MustCompile(`(?i)iban`), isIban},
+	{CardKind, regexp.MustCompile(`(?i)(card|^pan$|^cc_|credit)`), isCardNumber},
+	{NationalIdKind, regexp.MustCompile(`(?i)(ssn|passport|national_?id|tax_?id|^inn$|snils|personal_?id)`), isNationalId},
+	{IpKind, regexp.MustCompile(`(?i)(^ip$|ip_?addr|^ip_|_ip$|remote_?addr)`), isIp},
+	{PhoneKind, regexp.MustCompile(`(?i)(phone|mobile|^tel|_tel$|fax|msisdn)`), isPhone},
+	{BirthDateKind, regexp.MustCompile(`(?i)(birth|^dob$)`), nil},
+	{SecretKind, regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key)`), nil},
+	{NameKind, regexp.MustCompile(`(?i)(first_?name|last_?name|middle_?name|full_?name|surname|^name$|patronymic|nickname)`), nil},
+	{AddressKind, regexp.MustCompile(`(?i)(address|street|city|zip|post_?code|house|apartment)`), nil},
+}
+
+var (
+	emailPattern      = regexp.MustCompile(`^[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}$`)
+	phonePattern      = regexp.MustCompile(`^\+?[0-9 ()\-.]{7,20}$`)
+	ibanPattern       = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+	cardPattern       = regexp.MustCompile(`^[0-9][0-9 \-]{11,21}[0-9]$`)
+	nationalIdPattern = regexp.MustCompile(`^([0-9]{3}-[0-9]{2}-[0-9]{4}|[0-9]{3}-[0-9]{3}-[0-9]{3} ?[0-9]{2})$`)
+	nonDigitPattern   = regexp.MustCompile(`[^0-9]`)
+)
+
+func Classify(column ColumnSample) Suggestion {
+	best := Suggestion{Kind: NoneKind, Strategy: encoding.KeepStrategy}
+	for _, rule := range kindRules {
+		var reasons []string
+		nameScore, commentScore, valuesScore := 0.0, 0.0, 0.0
+		if rule.namePattern.MatchString(column.Name) {
+			nameScore = nameWeight
+			reasons = append(reasons, "column name")
+		}
+		if column.Comment != "" && rule.namePattern.MatchString(column.Comment) {
+			commentScore = commentWeight
+			reasons = append(reasons, "column comment")
+		}
+		if rule.valueMatches != nil && len(column.Values) > 0 {
+			ratio := getMatchRatio(column.Values, rule.valueMatches)
+			if ratio >= minValuesMatchRatio {
+				valuesScore = valuesWeight * ratio
+				reasons = append(reasons, "sample values")
+			}
+		}
+		confidence := 1 - (1-nameScore)*(1-commentScore)*(1-valuesScore)
+		if confidence > best.Confidence {
+			best = Suggestion{
+				Kind:       rule.kind,
+				Strategy:   suggestStrategy(column.Type),
+				Confidence: math.Round(confidence*100) / 100,
+				Reasons:    reasons,
+			}
+		}
+	}
+	return best
+}
+
+func suggestStrategy(dbType string) string {
+	if encoding.IsStringType(dbType) {
+		return encoding.HashStrategy
+	}
+	if encoding.IsNumericType(dbType) {
+		return encoding.NoiseStrategy
+	}
+	return encoding.NullStrategy
+}
+
+func getMatchRatio(values []string, matches func(value string) bool) float64 {
+	matched := 0
+	for _, value := range values {
+		if matches(strings.TrimSpace(value)) {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(values))
+}
+
+func isEmail(value string) bool {
+	return emailPattern.MatchString(value)
+}
+
+func isPhone(value string) bool {
+	digits := nonDigitPattern.ReplaceAllString(value, "")
+	return phonePattern.MatchString(value) && len(digits) >= 7 && len(digits) <= 15
+}
+
+func isIban(value string) bool {
+	value = strings.ToUpper(strings.ReplaceAll(value, " ", ""))
+	if !ibanPattern.MatchString(value) {
+		return false
+	}
+	//mod 97 check, letters are replaced by numbers 10..35
+	rearranged := value[4:] + value[:4]
+	remainder := 0
+	for _, r := range rearranged {
+		if r >= 'A' && r <= 'Z' {
+			remainder = (remainder*100 + int(r-'A'+10)) % 97
+		} else {
+			remainder = (remainder*10 + int(r-'0')) % 97
+		}
+	}
+	return remainder == 1
+}
+
+func isCardNumber(value string) bool {
+	if !cardPattern.MatchString(value) {
+		return false
+	}
+	digits := nonDigitPattern.ReplaceAllString(value, "")
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	//Luhn check
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func isNationalId(value string) bool {
+	return nationalIdPattern.MatchString(value)
+}
+
+func isIp(value string) bool {
+	return net.ParseIP(value) != nil
+}
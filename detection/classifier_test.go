@@ -0,0 +1,109 @@
+package detection
+
+import (
+	"obfuscator/encoding"
+	"testing"
+)
+
+func TestIsCardNumber(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"4111111111111111", true},
+		{"4111 1111 1111 1111", true},
+		{"4111-1111-1111-1111", true},
+		{"378282246310005", true},
+		{"6011000990139424", true},
+		{"4111111111111112", false},
+		{"4111 1111 1111 111a", false},
+		{"411111111111", false},
+		{"41111111111111111111", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if result := isCardNumber(test.value); result != test.expected {
+			t.Errorf("%q: got %v, expected %v", test.value, result, test.expected)
+		}
+	}
+}
+
+func TestIsIban(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"GB82WEST12345698765432", true},
+		{"GB82 WEST 1234 5698 7654 32", true},
+		{"gb82west12345698765432", true},
+		{"DE89370400440532013000", true},
+		{"DE89370400440532013001", false},
+		{"GB83WEST12345698765432", false},
+		{"GB82WEST", false},
+		{"1282WEST12345698765432", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if result := isIban(test.value); result != test.expected {
+			t.Errorf("%q: got %v, expected %v", test.value, result, test.expected)
+		}
+	}
+}
+
+func TestValueMatchers(t *testing.T) {
+	tests := []struct {
+		matches  func(string) bool
+		value    string
+		expected bool
+	}{
+		{isEmail, "john.doe+1@mail.example.com", true},
+		{isEmail, "john@localhost", false},
+		{isPhone, "+1 (555) 123-4567", true},
+		{isPhone, "123-45", false},
+		{isPhone, "+1234567890123456", false},
+		{isNationalId, "123-45-6789", true},
+		{isNationalId, "123-456-789 01", true},
+		{isNationalId, "123456789", false},
+		{isIp, "192.168.0.1", true},
+		{isIp, "2001:db8::1", true},
+		{isIp, "256.1.1.1", false},
+	}
+	for _, test := range tests {
+		if result := test.matches(test.value); result != test.expected {
+			t.Errorf("%q: got %v, expected %v", test.value, result, test.expected)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cards := []string{"4111111111111111", "378282246310005", "5555555555554444", "unknown"}
+	tests := []struct {
+		column     ColumnSample
+		kind       string
+		strategy   string
+		confidence float64
+	}{
+		{ColumnSample{Name: "customer_email", Type: "varchar(40)"}, EmailKind, encoding.HashStrategy, 0.6},
+		{ColumnSample{Name: "first_name", Type: encoding.TextType}, NameKind, encoding.HashStrategy, 0.6},
+		{ColumnSample{Name: "Name", Type: encoding.TextType}, NameKind, encoding.HashStrategy, 0.6},
+		{ColumnSample{Name: "username", Type: encoding.TextType}, NoneKind, encoding.KeepStrategy, 0},
+		{ColumnSample{Name: "zip", Type: encoding.IntType}, AddressKind, encoding.NoiseStrategy, 0.6},
+		{ColumnSample{Name: "client_ip", Type: "varbinary(16)"}, IpKind, encoding.NullStrategy, 0.6},
+		{ColumnSample{Name: "shipment_date", Type: encoding.TextType}, NoneKind, encoding.KeepStrategy, 0},
+		{ColumnSample{Name: "api_key", Type: encoding.TextType}, SecretKind, encoding.HashStrategy, 0.6},
+		{ColumnSample{Name: "c1", Type: encoding.TextType, Comment: "customer email"}, EmailKind,
+			encoding.HashStrategy, 0.4},
+		{ColumnSample{Name: "data", Type: encoding.TextType, Values: cards}, CardKind, encoding.HashStrategy, 0.68},
+		{ColumnSample{Name: "data", Type: encoding.TextType, Values: cards[3:]}, NoneKind, encoding.KeepStrategy, 0},
+		{ColumnSample{Name: "email", Type: encoding.TextType, Values: []string{" john@mail.com", "jane@mail.com"}},
+			EmailKind, encoding.HashStrategy, 0.96},
+	}
+	for _, test := range tests {
+		suggestion := Classify(test.column)
+		if suggestion.Kind != test.kind || suggestion.Strategy != test.strategy ||
+			suggestion.Confidence != test.confidence {
+			t.Errorf("%+v: got %v %v %v, expected %v %v %v", test.column, suggestion.Kind, suggestion.Strategy,
+				suggestion.Confidence, test.kind, test.strategy, test.confidence)
+		}
+	}
+}
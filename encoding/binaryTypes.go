@@ -0,0 +1,223 @@
+package encoding
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"obfuscator/config"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	//TruncateStrategy: count of bytes to keep
+	LengthParam = "length"
+)
+
+const (
+	pngMime     = "image/png"
+	jpegMime    = "image/jpeg"
+	gifMime     = "image/gif"
+	pdfMime     = "application/pdf"
+	unknownMime = "application/octet-stream"
+
+	//blobs sizes in bytes
+	tinyblobSize   = 255
+	blobSize       = 65535
+	mediumblobSize = 16777215
+	longblobSize   = 4294967295
+)
+
+var (
+	//built-in placeholders, images are 1x1 pixel, unknown types are replaced with empty value
+	placeholders = map[string][]byte{
+		pngMime:     encodePlaceholderImage(png.Encode),
+		jpegMime:    encodePlaceholderImage(func(w io.Writer, img image.Image) error { return jpeg.Encode(w, img, nil) }),
+		gifMime:     encodePlaceholderImage(func(w io.Writer, img image.Image) error { return gif.Encode(w, img, &gif.Options{NumColors: 2}) }),
+		pdfMime:     []byte(placeholderPdf),
+		unknownMime: {},
+	}
+	//placeholders set in config are read once
+	placeholderFiles      = make(map[string][]byte)
+	placeholderFilesMutex sync.Mutex
+)
+
+const placeholderPdf = "%PDF-1.4\n1 0 obj<</Type/Catalog/Pages 2 0 R>>endobj\n" +
+	"2 0 obj<</Type/Pages/Kids[3 0 R]/Count 1>>endobj\n" +
+	"3 0 obj<</Type/Page/Parent 2 0 R/MediaBox[0 0 1 1]>>endobj\n" +
+	"trailer<</Root 1 0 R>>\n%%EOF\n"
+
+func IsBinaryType(t string) bool {
+	return strings.HasPrefix(t, BinaryType+"(") || strings.HasPrefix(t, VarbinaryType+"(") ||
+		t == TinyblobType || t == BlobType || t == MediumblobType || t == LongblobType
+}
+
+//the same values get the same bytes of the same length
+func randomBytesValue(rawValue interface{}, dbType string, _ map[string]string) (interface{}, error) {
+	value, size, err := getBinaryValue(rawValue, dbType)
+	if err != nil {
+		return nil, err
+	}
+	return getDeterministicBytes(value, min(len(value), size)), nil
+}
+
+//placeholder files set in config override built-in placeholders
+func placeholderValue(rawValue interface{}, dbType string, _ map[string]string) (interface{}, error) {
+	value, size, err := getBinaryValue(rawValue, dbType)
+	if err != nil {
+		return nil, err
+	}
+	mime := strings.Split(http.DetectContentType(value), ";")[0]
+
+	var placeholder []byte
+	if path, exists := config.GetConfig().Obfuscator.Placeholders[mime]; exists {
+		placeholder, err = readPlaceholderFile(path)
+		if err != nil {
+			return nil, err
+		}
+	} else if placeholder, exists = placeholders[mime]; !exists {
+		placeholder = placeholders[unknownMime]
+	}
+	//the column is too short for the placeholder, so it's filled with bytes not related to the value
+	if len(placeholder) > size {
+		return getDeterministicBytes(value, size), nil
+	}
+	return placeholder, nil
+}
+
+//placeholder files set in config must be readable
+func validatePlaceholderParams(dbType string, params map[string]string) error {
+	if _, err := getBinarySize(dbType); err != nil {
+		return err
+	}
+	err := checkParams(params)
+	if err != nil {
+		return err
+	}
+	for mime, path := range config.GetConfig().Obfuscator.Placeholders {
+		if _, err = readPlaceholderFile(path); err != nil {
+			return fmt.Errorf("placeholder for %v: %v", mime, err)
+		}
+	}
+	return nil
+}
+
+func validateTruncateParams(dbType string, params map[string]string) error {
+	if _, err := getBinarySize(dbType); err != nil {
+		return err
+	}
+	err := checkParams(params, LengthParam)
+	if err != nil {
+		return err
+	}
+	if params[LengthParam] == "" {
+		return fmt.Errorf("param %v is required", LengthParam)
+	}
+	length, err := getIntParam(params, LengthParam, 0)
+	if err != nil {
+		return err
+	}
+	if length < 0 {
+		return fmt.Errorf("invalid param %v: negative length", LengthParam)
+	}
+	return nil
+}
+
+func truncateValue(rawValue interface{}, dbType string, params map[string]string) (interface{}, error) {
+	value, size, err := getBinaryValue(rawValue, dbType)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := params[LengthParam]; !exists {
+		return nil, fmt.Errorf("param %v is required", LengthParam)
+	}
+	length, err := getIntParam(params, LengthParam, 0)
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("invalid param %v: negative length", LengthParam)
+	}
+	return value[:min(len(value), size, int(length))], nil
+}
+
+func obfuscateBinary(rawValue interface{}, dbType string) (interface{}, error) {
+	return randomBytesValue(rawValue, dbType, nil)
+}
+
+func getBinaryValue(rawValue interface{}, dbType string) ([]byte, int, error) {
+	size, err := getBinarySize(dbType)
+	if err != nil {
+		return nil, 0, err
+	}
+	value, ok := rawValue.([]byte)
+	if !ok {
+		value = []byte(asString(rawValue))
+	}
+	return value, size, nil
+}
+
+func getBinarySize(dbType string) (int, error) {
+	switch dbType {
+	case TinyblobType:
+		return tinyblobSize, nil
+	case BlobType:
+		return blobSize, nil
+	case MediumblobType:
+		return mediumblobSize, nil
+	case LongblobType:
+		return longblobSize, nil
+	}
+	if !IsBinaryType(dbType) {
+		return 0, fmt.Errorf("strategy isn't applicable to type %v", dbType)
+	}
+	return strconv.Atoi(getSubstringInSingleLastBrackets(dbType))
+}
+
+//sha256 in counter mode keyed by the value
+func getDeterministicBytes(value []byte, length int) []byte {
+	seed := sha256.Sum256(value)
+	result := make([]byte, 0, length+sha256.Size)
+	counter := make([]byte, 8)
+	for i := uint64(0); len(result) < length; i++ {
+		binary.BigEndian.PutUint64(counter, i)
+		block := sha256.Sum256(append(seed[:], counter...))
+		result = append(result, block[:]...)
+	}
+	return result[:length]
+}
+
+func readPlaceholderFile(path string) ([]byte, error) {
+	placeholderFilesMutex.Lock()
+	defer placeholderFilesMutex.Unlock()
+	if content, exists := placeholderFiles[path]; exists {
+		return content, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	placeholderFiles[path] = content
+	return content, nil
+}
+
+func encodePlaceholderImage(encode func(io.Writer, image.Image) error) []byte {
+	img := image.NewGray(image.Rect(0, 0, 1, 1))
+	img.SetGray(0, 0, color.Gray{Y: 0xCC})
+	var buffer bytes.Buffer
+	err := encode(&buffer, img)
+	if err != nil {
+		panic(err)
+	}
+	return buffer.Bytes()
+}
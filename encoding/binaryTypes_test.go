@@ -0,0 +1,111 @@
+package encoding
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRandomBytesValue(t *testing.T) {
+	value := []byte("original value")
+	first, err := randomBytesValue(value, "varbinary(100)", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := randomBytesValue(value, "varbinary(100)", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.([]byte), second.([]byte)) {
+		t.Error("the same values must get the same bytes")
+	}
+	if len(first.([]byte)) != len(value) || bytes.Equal(first.([]byte), value) {
+		t.Errorf("unexpected result %x", first)
+	}
+
+	short, err := randomBytesValue(value, "binary(4)", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(short.([]byte)) != 4 {
+		t.Errorf("result is longer than column: %x", short)
+	}
+}
+
+func TestPlaceholderValue(t *testing.T) {
+	tests := []struct {
+		value []byte
+		mime  string
+	}{
+		{placeholders[pngMime], pngMime},
+		{placeholders[jpegMime], jpegMime},
+		{placeholders[gifMime], gifMime},
+		{[]byte(placeholderPdf), pdfMime},
+	}
+	for _, test := range tests {
+		if mime := strings.Split(http.DetectContentType(test.value), ";")[0]; mime != test.mime {
+			t.Fatalf("built-in placeholder of %v is detected as %v", test.mime, mime)
+		}
+		value, err := placeholderValue(append(append([]byte{}, test.value...), "secret"...), BlobType, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(value.([]byte), placeholders[test.mime]) {
+			t.Errorf("%v isn't replaced by placeholder", test.mime)
+		}
+	}
+
+	value, err := placeholderValue([]byte{0, 1, 2, 3}, BlobType, nil)
+	if err != nil || len(value.([]byte)) != 0 {
+		t.Errorf("unknown type must be replaced with empty value: %x, %v", value, err)
+	}
+
+	original := append(append([]byte{}, placeholders[pngMime]...), "secret"...)
+	value, err = placeholderValue(original, "binary(8)", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(value.([]byte)) != 8 || bytes.Equal(value.([]byte), original[:8]) {
+		t.Errorf("too long placeholder must be replaced by random bytes of column length: %x", value)
+	}
+}
+
+func TestTruncateValue(t *testing.T) {
+	value, err := truncateValue([]byte("abcdef"), BlobType, map[string]string{LengthParam: "3"})
+	if err != nil || string(value.([]byte)) != "abc" {
+		t.Errorf("unexpected result %q, %v", value, err)
+	}
+	value, err = truncateValue([]byte("abcdef"), "varbinary(2)", map[string]string{LengthParam: "3"})
+	if err != nil || string(value.([]byte)) != "ab" {
+		t.Errorf("result must fit column: %q, %v", value, err)
+	}
+	if _, err = truncateValue([]byte("abc"), BlobType, nil); err == nil {
+		t.Error("length is required")
+	}
+}
+
+func TestValidateBinaryStrategies(t *testing.T) {
+	tests := []struct {
+		strategy string
+		dbType   string
+		params   map[string]string
+		valid    bool
+	}{
+		{RandomBytesStrategy, "binary(16)", nil, true},
+		{RandomBytesStrategy, "varchar(16)", nil, false},
+		{PlaceholderStrategy, LongblobType, nil, true},
+		{PlaceholderStrategy, BlobType, map[string]string{"mime": pngMime}, false},
+		{TruncateStrategy, BlobType, map[string]string{LengthParam: "10"}, true},
+		{TruncateStrategy, BlobType, nil, false},
+		{TruncateStrategy, BlobType, map[string]string{LengthParam: "-1"}, false},
+		{TruncateStrategy, BlobType, map[string]string{LengthParam: "ten"}, false},
+		{TruncateStrategy, "text", map[string]string{LengthParam: "10"}, false},
+	}
+	for _, test := range tests {
+		err := ValidateStrategy(test.strategy, test.dbType, test.params)
+		if (err == nil) != test.valid {
+			t.Errorf("%v %v %v: unexpected result %v", test.strategy, test.dbType, test.params, err)
+		}
+	}
+}
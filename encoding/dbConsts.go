@@ -25,6 +25,31 @@ const (
 	TextType       = "text"
 	MediumtextType = "mediumtext"
 	LongtextType   = "longtext"
+
+	EnumType    = "enum"       //enum('a','b')
+	SetType     = "set"        //set('a','b')
+	BitType     = "bit"        //default (1)
+	BooleanType = "tinyint(1)" //BOOL and BOOLEAN are shown as tinyint(1)
+	YearType    = "year"
+
+	JsonType = "json"
+
+	BinaryType     = "binary"    //default (1)
+	VarbinaryType  = "varbinary" //no default
+	TinyblobType   = "tinyblob"
+	BlobType       = "blob"
+	MediumblobType = "mediumblob"
+	LongblobType   = "longblob"
+
+	GeometryType           = "geometry"
+	PointType              = "point"
+	LinestringType         = "linestring"
+	PolygonType            = "polygon"
+	MultipointType         = "multipoint"
+	MultilinestringType    = "multilinestring"
+	MultipolygonType       = "multipolygon"
+	GeomcollectionType     = "geomcollection"
+	GeometrycollectionType = "geometrycollection" //shown by mysql before 8.0.11
 )
 
 //obfuscating bounds
@@ -45,4 +70,7 @@ const (
 	UpperBoundUMediumint = 16777215
 	UpperBoundUInt       = 4294967295
 	UpperBoundUBigint    = 18446744073709551615
+
+	LowerBoundYear = 1901
+	UpperBoundYear = 2155
 )
@@ -0,0 +1,343 @@
+package encoding
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//strategies params
+const (
+	//MemberStrategy: uniform (default) or preserve
+	DistributionParam = "distribution"
+	//MemberStrategy: JSON object value -> count, collected from origin automatically for preserve distribution
+	WeightsParam = "weights"
+	//BitStrategy: random (default) or flip
+	ModeParam = "mode"
+	//YearStrategy: max shift in years, 10 by default
+	RangeParam = "range"
+	//YearStrategy: bounds of result, mysql YEAR bounds by default
+	MinParam = "min"
+	MaxParam = "max"
+)
+
+const (
+	UniformDistribution  = "uniform"
+	PreserveDistribution = "preserve"
+
+	RandomMode = "random"
+	FlipMode   = "flip"
+
+	defaultYearRange = 10
+	setSeparator     = ","
+)
+
+func obfuscateDiscrete(rawValue interface{}, dbType string) (interface{}, error) {
+	switch {
+	case IsEnumType(dbType), IsSetType(dbType):
+		return memberValue(rawValue, dbType, nil)
+	case IsBitType(dbType), IsBooleanType(dbType):
+		return bitValue(rawValue, dbType, nil)
+	case IsYearType(dbType):
+		return yearValue(rawValue, dbType, nil)
+	default:
+		return rawValue, nil
+	}
+}
+
+//ENUM value is replaced by another member, SET value by another subset of members.
+//With weights values are taken from original distribution, so the value can stay the same
+func memberValue(rawValue interface{}, dbType string, params map[string]string) (interface{}, error) {
+	if !IsEnumType(dbType) && !IsSetType(dbType) {
+		return nil, fmt.Errorf("member strategy isn't applicable to type %v", dbType)
+	}
+	members, err := parseMembers(dbType)
+	if err != nil {
+		return nil, err
+	}
+	value := asString(rawValue)
+
+	switch params[DistributionParam] {
+	case "", UniformDistribution:
+	case PreserveDistribution:
+		if params[WeightsParam] == "" {
+			return nil, fmt.Errorf("weights of column values aren't collected")
+		}
+	default:
+		return nil, fmt.Errorf("unknown distribution %v", params[DistributionParam])
+	}
+	if params[WeightsParam] != "" {
+		return pickWeighted(params[WeightsParam])
+	}
+
+	if IsEnumType(dbType) {
+		return pickOtherMember(value, members), nil
+	}
+	return pickOtherSubset(value, members), nil
+}
+
+//enum('a','b''c') -> [a b'c]
+func parseMembers(dbType string) ([]string, error) {
+	start := strings.Index(dbType, "(")
+	end := strings.LastIndex(dbType, ")")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("can't parse members of type %v", dbType)
+	}
+	list := dbType[start+1 : end]
+
+	var members []string
+	var sb strings.Builder
+	quoted := false
+	for i := 0; i < len(list); i++ {
+		c := list[i]
+		switch {
+		case quoted && c == '\'' && i+1 < len(list) && list[i+1] == '\'':
+			sb.WriteByte(c)
+			i++
+		case c == '\'':
+			quoted = !quoted
+			if !quoted {
+				members = append(members, sb.String())
+				sb.Reset()
+			}
+		case quoted:
+			sb.WriteByte(c)
+		}
+	}
+	if quoted || len(members) == 0 {
+		return nil, fmt.Errorf("can't parse members of type %v", dbType)
+	}
+	return members, nil
+}
+
+func pickOtherMember(value string, members []string) string {
+	var candidates []string
+	for _, member := range members {
+		if member != value {
+			candidates = append(candidates, member)
+		}
+	}
+	if len(candidates) == 0 {
+		return value
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
+
+//every member is taken with 1/2 probability, members order of type is kept as mysql does
+func pickOtherSubset(value string, members []string) string {
+	//2^n subsets, the original one is skipped if there's another
+	for attempt := 0; attempt < 10; attempt++ {
+		var subset []string
+		for _, member := range members {
+			if getRandBool() {
+				subset = append(subset, member)
+			}
+		}
+		result := strings.Join(subset, setSeparator)
+		if result != value {
+			return result
+		}
+	}
+	return value
+}
+
+func pickWeighted(weightsJson string) (string, error) {
+	var weights map[string]int64
+	err := json.Unmarshal([]byte(weightsJson), &weights)
+	if err != nil {
+		return "", fmt.Errorf("invalid weights: %v", err)
+	}
+	var values []string
+	var total int64
+	for value, weight := range weights {
+		if weight < 0 {
+			return "", fmt.Errorf("negative weight of value %v", value)
+		}
+		values = append(values, value)
+		total += weight
+	}
+	if total == 0 {
+		return "", fmt.Errorf("weights are empty")
+	}
+	//map order is random
+	sort.Strings(values)
+	point := rand.Int63n(total)
+	for _, value := range values {
+		point -= weights[value]
+		if point < 0 {
+			return value, nil
+		}
+	}
+	return values[len(values)-1], nil
+}
+
+//BIT(n) values are read as big-endian bytes, BOOLEAN values as numbers
+func bitValue(rawValue interface{}, dbType string, params map[string]string) (interface{}, error) {
+	mode := params[ModeParam]
+	if mode == "" {
+		mode = RandomMode
+	}
+	if mode != RandomMode && mode != FlipMode {
+		return nil, fmt.Errorf("unknown mode %v", mode)
+	}
+
+	if IsBooleanType(dbType) {
+		value, err := strconv.ParseInt(asString(rawValue), 10, 8)
+		if err != nil {
+			return nil, err
+		}
+		if mode == FlipMode {
+			if value == 0 {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		}
+		return int64(rand.Intn(2)), nil
+	}
+
+	if !IsBitType(dbType) {
+		return nil, fmt.Errorf("bit strategy isn't applicable to type %v", dbType)
+	}
+	size := 1
+	if strings.Contains(dbType, "(") {
+		var err error
+		size, err = strconv.Atoi(getSubstringInSingleLastBrackets(dbType))
+		if err != nil {
+			return nil, err
+		}
+	}
+	bytes, ok := rawValue.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value of type %v", dbType)
+	}
+	result := make([]byte, (size+7)/8)
+	//shorter values are aligned to the right
+	copy(result[len(result)-min(len(bytes), len(result)):], bytes)
+	for i := range result {
+		if mode == FlipMode {
+			result[i] = ^result[i]
+		} else {
+			result[i] = byte(rand.Intn(256))
+		}
+	}
+	if size%8 != 0 {
+		result[0] &= byte(1<<(size%8)) - 1
+	}
+	return result, nil
+}
+
+//shifts the year by random number of years within the range, zero year is kept
+func yearValue(rawValue interface{}, dbType string, params map[string]string) (interface{}, error) {
+	if !IsYearType(dbType) {
+		return nil, fmt.Errorf("year strategy isn't applicable to type %v", dbType)
+	}
+	value, err := strconv.ParseInt(asString(rawValue), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if value == 0 {
+		return value, nil
+	}
+	yearRange, lowerBound, upperBound, err := getYearParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	value += rand.Int63n(2*yearRange+1) - yearRange
+	if value < lowerBound {
+		value = lowerBound
+	}
+	if value > upperBound {
+		value = upperBound
+	}
+	return value, nil
+}
+
+//bounds are limited by YEAR type and range by bounds, so the shift can't overflow
+func getYearParams(params map[string]string) (yearRange, lowerBound, upperBound int64, err error) {
+	yearRange, err = getIntParam(params, RangeParam, defaultYearRange)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	lowerBound, err = getIntParam(params, MinParam, LowerBoundYear)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	upperBound, err = getIntParam(params, MaxParam, UpperBoundYear)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if lowerBound < LowerBoundYear || upperBound > UpperBoundYear || lowerBound > upperBound {
+		return 0, 0, 0, fmt.Errorf("invalid year bounds %v..%v, must be within %v..%v", lowerBound, upperBound,
+			LowerBoundYear, UpperBoundYear)
+	}
+	if yearRange < 0 || yearRange > upperBound-lowerBound {
+		return 0, 0, 0, fmt.Errorf("invalid year range %v, must be within 0..%v", yearRange, upperBound-lowerBound)
+	}
+	return yearRange, lowerBound, upperBound, nil
+}
+
+func getIntParam(params map[string]string, name string, defaultValue int64) (int64, error) {
+	rawValue, exists := params[name]
+	if !exists || rawValue == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.ParseInt(rawValue, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid param %v: %v", name, err)
+	}
+	return value, nil
+}
+
+func validateMemberParams(dbType string, params map[string]string) error {
+	if !IsEnumType(dbType) && !IsSetType(dbType) {
+		return fmt.Errorf("member strategy isn't applicable to type %v", dbType)
+	}
+	err := checkParams(params, DistributionParam, WeightsParam)
+	if err != nil {
+		return err
+	}
+	if _, err = parseMembers(dbType); err != nil {
+		return err
+	}
+	switch params[DistributionParam] {
+	case "", UniformDistribution, PreserveDistribution:
+	default:
+		return fmt.Errorf("unknown distribution %v", params[DistributionParam])
+	}
+	if params[WeightsParam] != "" {
+		_, err = pickWeighted(params[WeightsParam])
+	}
+	return err
+}
+
+func validateBitParams(dbType string, params map[string]string) error {
+	if !IsBitType(dbType) && !IsBooleanType(dbType) {
+		return fmt.Errorf("bit strategy isn't applicable to type %v", dbType)
+	}
+	err := checkParams(params, ModeParam)
+	if err != nil {
+		return err
+	}
+	switch params[ModeParam] {
+	case "", RandomMode, FlipMode:
+		return nil
+	default:
+		return fmt.Errorf("unknown mode %v", params[ModeParam])
+	}
+}
+
+func validateYearParams(dbType string, params map[string]string) error {
+	if !IsYearType(dbType) {
+		return fmt.Errorf("year strategy isn't applicable to type %v", dbType)
+	}
+	err := checkParams(params, RangeParam, MinParam, MaxParam)
+	if err != nil {
+		return err
+	}
+	_, _, _, err = getYearParams(params)
+	return err
+}
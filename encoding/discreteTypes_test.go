@@ -0,0 +1,175 @@
+package encoding
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseMembers(t *testing.T) {
+	tests := []struct {
+		dbType   string
+		expected []string
+	}{
+		{"enum('a','b')", []string{"a", "b"}},
+		{"enum('it''s','x,y')", []string{"it's", "x,y"}},
+		{"set('read','write','admin')", []string{"read", "write", "admin"}},
+		{"enum('')", []string{""}},
+	}
+	for _, test := range tests {
+		members, err := parseMembers(test.dbType)
+		if err != nil {
+			t.Fatalf("%v: %v", test.dbType, err)
+		}
+		if strings.Join(members, "|") != strings.Join(test.expected, "|") {
+			t.Errorf("%v: got %q, expected %q", test.dbType, members, test.expected)
+		}
+	}
+	for _, dbType := range []string{"enum", "enum()", "enum('a"} {
+		if _, err := parseMembers(dbType); err == nil {
+			t.Errorf("%v: error expected", dbType)
+		}
+	}
+}
+
+func TestMemberValue(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		value, err := memberValue([]byte("b"), "enum('a','b','c')", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != "a" && value != "c" {
+			t.Fatalf("enum value %v isn't another member", value)
+		}
+
+		value, err = memberValue([]byte("read,write"), "set('read','write','admin')", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value == "read,write" {
+			t.Fatalf("set value isn't changed")
+		}
+		for _, member := range strings.Split(value.(string), setSeparator) {
+			if member != "" && member != "read" && member != "write" && member != "admin" {
+				t.Fatalf("unknown member %v", member)
+			}
+		}
+
+		value, err = memberValue([]byte("a"), "enum('a','b','c')", map[string]string{
+			DistributionParam: PreserveDistribution,
+			WeightsParam:      `{"c": 5, "a": 0}`,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != "c" {
+			t.Fatalf("value %v hasn't weight", value)
+		}
+	}
+
+	_, err := memberValue([]byte("a"), "enum('a','b')", map[string]string{DistributionParam: PreserveDistribution})
+	if err == nil {
+		t.Error("preserve distribution without weights must fail")
+	}
+}
+
+func TestPickWeighted(t *testing.T) {
+	for _, weights := range []string{`{}`, `{"a": 0}`, `{"a": -1}`, `[1]`} {
+		if _, err := pickWeighted(weights); err == nil {
+			t.Errorf("%v: error expected", weights)
+		}
+	}
+}
+
+func TestBitValue(t *testing.T) {
+	value, err := bitValue([]byte{0x01, 0x0F}, "bit(10)", map[string]string{ModeParam: FlipMode})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(value.([]byte), []byte{0x02, 0xF0}) {
+		t.Errorf("flipped bit(10) is %x", value)
+	}
+
+	value, err = bitValue([]byte{0x01}, "bit(16)", map[string]string{ModeParam: FlipMode})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(value.([]byte), []byte{0xFF, 0xFE}) {
+		t.Errorf("short value isn't aligned to the right: %x", value)
+	}
+
+	for i := 0; i < 100; i++ {
+		value, err = bitValue([]byte{0}, "bit(3)", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.([]byte)[0] > 7 {
+			t.Fatalf("random bit(3) value %x is out of size", value)
+		}
+	}
+
+	value, err = bitValue(int64(0), BooleanType, map[string]string{ModeParam: FlipMode})
+	if err != nil || value != int64(1) {
+		t.Errorf("flipped boolean is %v, %v", value, err)
+	}
+}
+
+func TestYearValue(t *testing.T) {
+	params := map[string]string{RangeParam: "5", MinParam: "2000", MaxParam: "2010"}
+	for i := 0; i < 100; i++ {
+		value, err := yearValue([]byte("2001"), YearType, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		year := value.(int64)
+		if year < 2000 || year > 2006 {
+			t.Fatalf("year %v is out of range", year)
+		}
+	}
+	value, err := yearValue([]byte("0000"), YearType, params)
+	if err != nil || value != int64(0) {
+		t.Errorf("zero year is changed to %v, %v", value, err)
+	}
+	if _, err = yearValue([]byte("2001"), YearType, map[string]string{RangeParam: "4611686018427387904"}); err == nil {
+		t.Errorf("range exceeding bounds must fail")
+	}
+}
+
+func TestValidateDiscreteStrategies(t *testing.T) {
+	tests := []struct {
+		strategy string
+		dbType   string
+		params   map[string]string
+		valid    bool
+	}{
+		{MemberStrategy, "enum('a','b')", nil, true},
+		{MemberStrategy, "set('a','b')", map[string]string{DistributionParam: PreserveDistribution}, true},
+		{MemberStrategy, "varchar(10)", nil, false},
+		{MemberStrategy, "enum('a','b')", map[string]string{DistributionParam: "preserved"}, false},
+		{MemberStrategy, "enum('a','b')", map[string]string{"distrbution": UniformDistribution}, false},
+		{MemberStrategy, "enum('a','b')", map[string]string{WeightsParam: "{"}, false},
+		{BitStrategy, "bit(4)", map[string]string{ModeParam: FlipMode}, true},
+		{BitStrategy, BooleanType, nil, true},
+		{BitStrategy, "int", nil, false},
+		{BitStrategy, "bit(4)", map[string]string{ModeParam: "flipped"}, false},
+		{YearStrategy, YearType, map[string]string{RangeParam: "3", MinParam: "1990"}, true},
+		{YearStrategy, YearType, map[string]string{RangeParam: "three"}, false},
+		{YearStrategy, YearType, map[string]string{MinParam: "2010", MaxParam: "2000"}, false},
+		{YearStrategy, YearType, map[string]string{RangeParam: "4611686018427387904"}, false},
+		{YearStrategy, YearType, map[string]string{RangeParam: "11", MinParam: "2000", MaxParam: "2010"}, false},
+		{YearStrategy, YearType, map[string]string{RangeParam: "10", MinParam: "2000", MaxParam: "2010"}, true},
+		{YearStrategy, YearType, map[string]string{MinParam: "-9223372036854775808"}, false},
+		{YearStrategy, YearType, map[string]string{MaxParam: "3000"}, false},
+		{YearStrategy, "int", nil, false},
+		{HashStrategy, "int", nil, false},
+		{NoiseStrategy, "int", map[string]string{"percent": "10"}, false},
+		{DefaultStrategy, "int", nil, true},
+		{"unknown", "int", nil, false},
+	}
+	for _, test := range tests {
+		err := ValidateStrategy(test.strategy, test.dbType, test.params)
+		if (err == nil) != test.valid {
+			t.Errorf("%v %v %v: unexpected result %v", test.strategy, test.dbType, test.params, err)
+		}
+	}
+}
@@ -35,6 +35,18 @@ func ObfuscateValue(rawValue *interface{}, dbType string) (interface{}, error) {
 		}
 		return value, nil
 	}
+	if IsDiscreteType(dbType) {
+		return obfuscateDiscrete(*rawValue, dbType)
+	}
+	if IsJsonType(dbType) {
+		return obfuscateJson(*rawValue)
+	}
+	if IsBinaryType(dbType) {
+		return obfuscateBinary(*rawValue, dbType)
+	}
+	if IsSpatialType(dbType) {
+		return geoValue(*rawValue, dbType, nil)
+	}
 
 	switch dbType {
 	case TinyintType, SmallintType, MediumintType, IntType, BigintType:
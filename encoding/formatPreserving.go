@@ -0,0 +1,241 @@
+package encoding
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"obfuscator/fpe"
+	"obfuscator/secrets"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//FpeStrategy params
+const (
+	//ff1 (default) or ff3-1
+	AlgorithmParam = "algorithm"
+	//the same tweak must be used for re-identification, e.g. name of the column
+	TweakParam = "tweak"
+)
+
+const (
+	FF1Algorithm  = "ff1"
+	FF31Algorithm = "ff3-1"
+)
+
+const (
+	//26^5 letters are enough for the min domain, 26^4 aren't
+	fpeMinStringSize = 5
+	//FF3-1 encrypts at most 192 bits, 26^40 letters fit, 26^41 don't
+	fpeMaxFF31StringSize = 40
+)
+
+var (
+	fpeCiphers      = make(map[string]fpe.Cipher)
+	fpeCiphersMutex sync.Mutex
+)
+
+//one character of the value which is encrypted, the others are kept as is
+type fpePosition struct {
+	index int
+	base  rune
+	radix int64
+}
+
+//digits, lower and upper case latin letters are kept in their classes and positions, so the value fits the column,
+//other characters are kept as is.
+//All these characters are one number of mixed radix which is encrypted by binary FF1 or FF3-1 with cycle walking.
+//Values with less than 10^6 possible results are rejected as insecure, the job is failed then
+//because original value can't be inserted to the column which is expected to be encrypted.
+//Empty strings have nothing to encrypt and are kept
+func fpeValue(rawValue interface{}, dbType string, params map[string]string) (interface{}, error) {
+	original := asString(rawValue)
+	if original == "" {
+		return rawValue, nil
+	}
+	value, err := transformFormatPreserving(original, dbType, params, true)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoFallback, err)
+	}
+	return value, nil
+}
+
+//columns which can't hold values of 10^6 possible results are rejected,
+//e.g. SMALLINT has at most 5 digits and CHAR(4) at most 26^4 combinations of letters.
+//FF3-1 has max length, so it's applicable only to integers and strings of declared length which fits it
+func validateFpeParams(dbType string, params map[string]string) error {
+	switch params[AlgorithmParam] {
+	case "", FF1Algorithm, FF31Algorithm:
+	default:
+		return fmt.Errorf("unknown algorithm %v", params[AlgorithmParam])
+	}
+	isFF31 := params[AlgorithmParam] == FF31Algorithm
+	switch {
+	case dbType == TinyintType || dbType == UTinyintType || dbType == SmallintType || dbType == USmallintType:
+		return fmt.Errorf("type %v is too small for format-preserving encryption", dbType)
+	case strings.HasPrefix(dbType, CharType) || strings.HasPrefix(dbType, VarcharType):
+		size, err := strconv.Atoi(getSubstringInSingleLastBrackets(dbType))
+		if err != nil {
+			return err
+		}
+		if size < fpeMinStringSize {
+			return fmt.Errorf("type %v is too small for format-preserving encryption", dbType)
+		}
+		if isFF31 && size > fpeMaxFF31StringSize {
+			return fmt.Errorf("type %v is too large for %v, max length is %v", dbType, FF31Algorithm,
+				fpeMaxFF31StringSize)
+		}
+	case IsIntType(dbType) || IsUintType(dbType):
+	case IsStringType(dbType):
+		if isFF31 {
+			return fmt.Errorf("type %v is too large for %v, max length is %v", dbType, FF31Algorithm,
+				fpeMaxFF31StringSize)
+		}
+	default:
+		return fmt.Errorf("fpe strategy isn't applicable to type %v", dbType)
+	}
+	return checkParams(params, AlgorithmParam, TweakParam)
+}
+
+//decrypts value obfuscated by fpe strategy with the same type and params
+func RevealFormatPreserving(value, dbType string, params map[string]string) (string, error) {
+	return transformFormatPreserving(value, dbType, params, false)
+}
+
+func transformFormatPreserving(value, dbType string, params map[string]string, encrypt bool) (string, error) {
+	isInt := IsIntType(dbType) || IsUintType(dbType)
+	if !isInt && !IsStringType(dbType) {
+		return "", fmt.Errorf("fpe strategy isn't applicable to type %v", dbType)
+	}
+	cipher, tweak, err := getFpeCipher(params[AlgorithmParam], params[TweakParam])
+	if err != nil {
+		return "", err
+	}
+
+	runes := []rune(value)
+	positions, err := getFpePositions(runes, isInt)
+	if err != nil {
+		return "", err
+	}
+	domainSize := big.NewInt(1)
+	for _, position := range positions {
+		domainSize.Mul(domainSize, big.NewInt(position.radix))
+	}
+	if domainSize.Cmp(big.NewInt(fpe.MinDomainSize)) < 0 {
+		return "", fmt.Errorf("value is too short for format-preserving encryption")
+	}
+	bitLength := new(big.Int).Sub(domainSize, big.NewInt(1)).BitLen()
+
+	number := big.NewInt(0)
+	for _, position := range positions {
+		number.Mul(number, big.NewInt(position.radix))
+		number.Add(number, big.NewInt(int64(runes[position.index]-position.base)))
+	}
+
+	//cycle walking: result is encrypted again until it's in the domain, decryption walks back the same way
+	for {
+		bits := make([]int, bitLength)
+		for i := range bits {
+			bits[i] = int(number.Bit(bitLength - 1 - i))
+		}
+		if encrypt {
+			bits, err = cipher.Encrypt(bits, tweak)
+		} else {
+			bits, err = cipher.Decrypt(bits, tweak)
+		}
+		if err != nil {
+			return "", err
+		}
+		number = big.NewInt(0)
+		for _, bit := range bits {
+			number.Lsh(number, 1)
+			number.Or(number, big.NewInt(int64(bit)))
+		}
+		if number.Cmp(domainSize) >= 0 {
+			continue
+		}
+
+		result := make([]rune, len(runes))
+		copy(result, runes)
+		rest := new(big.Int).Set(number)
+		remainder := new(big.Int)
+		for i := len(positions) - 1; i >= 0; i-- {
+			rest.DivMod(rest, big.NewInt(positions[i].radix), remainder)
+			result[positions[i].index] = positions[i].base + rune(remainder.Int64())
+		}
+		if isInt && !fitsIntType(string(result), dbType) {
+			continue
+		}
+		return string(result), nil
+	}
+}
+
+//the first digit of integer isn't zero not to change its length
+func getFpePositions(runes []rune, isInt bool) ([]fpePosition, error) {
+	var positions []fpePosition
+	for i, r := range runes {
+		switch {
+		case isInt && i == 0 && r == '-':
+		case isInt && (r < '0' || r > '9'):
+			return nil, fmt.Errorf("value isn't integer")
+		case isInt && r != '0' && (i == 0 || i == 1 && runes[0] == '-') && i < len(runes)-1:
+			positions = append(positions, fpePosition{index: i, base: '1', radix: 9})
+		case r >= '0' && r <= '9':
+			positions = append(positions, fpePosition{index: i, base: '0', radix: 10})
+		case r >= 'a' && r <= 'z':
+			positions = append(positions, fpePosition{index: i, base: 'a', radix: 26})
+		case r >= 'A' && r <= 'Z':
+			positions = append(positions, fpePosition{index: i, base: 'A', radix: 26})
+		}
+	}
+	return positions, nil
+}
+
+func fitsIntType(value, dbType string) bool {
+	var err error
+	if IsIntType(dbType) {
+		_, err = getInt(value, dbType)
+	} else {
+		_, err = getUint(value, dbType)
+	}
+	return err == nil
+}
+
+func getFpeCipher(algorithm, tweak string) (fpe.Cipher, []byte, error) {
+	if algorithm == "" {
+		algorithm = FF1Algorithm
+	}
+	var tweakBytes []byte
+	switch algorithm {
+	case FF1Algorithm:
+		tweakBytes = []byte(tweak)
+	case FF31Algorithm:
+		//FF3-1 tweak has fixed size
+		hash := sha256.Sum256([]byte(tweak))
+		tweakBytes = hash[:fpe.FF31TweakSize]
+	default:
+		return nil, nil, fmt.Errorf("unknown algorithm %v", algorithm)
+	}
+
+	fpeCiphersMutex.Lock()
+	defer fpeCiphersMutex.Unlock()
+	if cipher, exists := fpeCiphers[algorithm]; exists {
+		return cipher, tweakBytes, nil
+	}
+	key, err := secrets.GetFpeKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	var cipher fpe.Cipher
+	if algorithm == FF1Algorithm {
+		cipher, err = fpe.NewFF1(key, 2)
+	} else {
+		cipher, err = fpe.NewFF31(key, 2)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	fpeCiphers[algorithm] = cipher
+	return cipher, tweakBytes, nil
+}
@@ -0,0 +1,160 @@
+package encoding
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	//key files aren't used by tests
+	os.Setenv("OBFUSCATOR_FPE_KEY", "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	os.Exit(m.Run())
+}
+
+func checkFpeRoundTrip(t *testing.T, value, dbType string, params map[string]string) string {
+	encrypted, err := fpeValue(value, dbType, params)
+	if err != nil {
+		t.Fatalf("%v %v: %v", value, dbType, err)
+	}
+	decrypted, err := RevealFormatPreserving(encrypted.(string), dbType, params)
+	if err != nil {
+		t.Fatalf("%v %v: %v", value, dbType, err)
+	}
+	if decrypted != value {
+		t.Errorf("%v %v: decrypted %v from %v", value, dbType, decrypted, encrypted)
+	}
+	return encrypted.(string)
+}
+
+func TestFpeStringFormat(t *testing.T) {
+	for _, algorithm := range []string{FF1Algorithm, FF31Algorithm} {
+		params := map[string]string{AlgorithmParam: algorithm, TweakParam: "users.phone"}
+		value := "+1 (555) 010-9999 ext. Ab"
+		encrypted := []rune(checkFpeRoundTrip(t, value, "varchar(32)", params))
+		for i, r := range []rune(value) {
+			switch {
+			case r >= '0' && r <= '9':
+				if encrypted[i] < '0' || encrypted[i] > '9' {
+					t.Errorf("%v: digit %c is encrypted to %c", algorithm, r, encrypted[i])
+				}
+			case r >= 'a' && r <= 'z':
+				if encrypted[i] < 'a' || encrypted[i] > 'z' {
+					t.Errorf("%v: lower case %c is encrypted to %c", algorithm, r, encrypted[i])
+				}
+			case r >= 'A' && r <= 'Z':
+				if encrypted[i] < 'A' || encrypted[i] > 'Z' {
+					t.Errorf("%v: upper case %c is encrypted to %c", algorithm, r, encrypted[i])
+				}
+			default:
+				if encrypted[i] != r {
+					t.Errorf("%v: separator %c is changed to %c", algorithm, r, encrypted[i])
+				}
+			}
+		}
+	}
+}
+
+//domain of 7 digits integer is 9*10^6, so about a half of binary results are out of it and are encrypted again
+func TestFpeCycleWalking(t *testing.T) {
+	for _, algorithm := range []string{FF1Algorithm, FF31Algorithm} {
+		params := map[string]string{AlgorithmParam: algorithm}
+		results := make(map[string]bool)
+		for i := 1000000; i < 1000200; i++ {
+			encrypted := checkFpeRoundTrip(t, strconv.Itoa(i), IntType, params)
+			if len(encrypted) != 7 || encrypted[0] == '0' {
+				t.Errorf("%v: %v is encrypted to %v", algorithm, i, encrypted)
+			}
+			results[encrypted] = true
+		}
+		if len(results) != 200 {
+			t.Errorf("%v: encryption isn't injective, %v results of 200 values", algorithm, len(results))
+		}
+	}
+}
+
+//encrypted value must fit the column, otherwise it's encrypted again
+func TestFpeIntFit(t *testing.T) {
+	tests := []struct {
+		dbType string
+		first  int64
+		min    int64
+		max    int64
+	}{
+		{MediumintType, 8388000, 1000000, 8388607},
+		{MediumintType, -8388000, -8388608, -1000000},
+		{UMediumintType, 16777000, 10000000, 16777215},
+		{BigintType, -9223372036854775000, -9223372036854775808, -1000000000000000000},
+	}
+	for _, test := range tests {
+		for i := int64(0); i < 100; i++ {
+			value := strconv.FormatInt(test.first+i, 10)
+			encrypted := checkFpeRoundTrip(t, value, test.dbType, nil)
+			number, err := strconv.ParseInt(encrypted, 10, 64)
+			if err != nil || number < test.min || number > test.max {
+				t.Errorf("%v %v is encrypted to %v, %v", test.dbType, value, encrypted, err)
+			}
+		}
+	}
+}
+
+func TestFpeSmallDomain(t *testing.T) {
+	for _, value := range []string{"12345", "ab12", "-99999"} {
+		_, err := fpeValue(value, "varchar(10)", nil)
+		if !errors.Is(err, ErrNoFallback) {
+			t.Errorf("%v: original value mustn't be kept, got %v", value, err)
+		}
+	}
+	if _, err := fpeValue("12a", IntType, nil); !errors.Is(err, ErrNoFallback) {
+		t.Errorf("not integer value mustn't be kept, got %v", err)
+	}
+	if value, err := fpeValue([]byte{}, "varchar(10)", nil); err != nil || asString(value) != "" {
+		t.Errorf("empty value must be kept, got %v, %v", value, err)
+	}
+}
+
+//the longest string allowed for FF3-1 fits its max length
+func TestFpeMaxFF31String(t *testing.T) {
+	params := map[string]string{AlgorithmParam: FF31Algorithm}
+	dbType := fmt.Sprintf("varchar(%v)", fpeMaxFF31StringSize)
+	original := strings.Repeat("z", fpeMaxFF31StringSize)
+	encrypted, err := fpeValue(original, dbType, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revealed, err := RevealFormatPreserving(encrypted.(string), dbType, params)
+	if err != nil || revealed != original {
+		t.Errorf("got %v, expected %v, %v", revealed, original, err)
+	}
+}
+
+func TestValidateFpeParams(t *testing.T) {
+	tests := []struct {
+		dbType string
+		params map[string]string
+		valid  bool
+	}{
+		{IntType, nil, true},
+		{"varchar(5)", map[string]string{AlgorithmParam: FF31Algorithm, TweakParam: "t"}, true},
+		{TextType, map[string]string{AlgorithmParam: FF1Algorithm}, true},
+		{"varchar(40)", map[string]string{AlgorithmParam: FF31Algorithm}, true},
+		{IntType, map[string]string{AlgorithmParam: FF31Algorithm}, true},
+		{"varchar(41)", map[string]string{AlgorithmParam: FF31Algorithm}, false},
+		{TextType, map[string]string{AlgorithmParam: FF31Algorithm}, false},
+		{SmallintType, nil, false},
+		{UTinyintType, nil, false},
+		{"char(4)", nil, false},
+		{DoubleType, nil, false},
+		{IntType, map[string]string{AlgorithmParam: "aes"}, false},
+		{IntType, map[string]string{"tweek": "t"}, false},
+	}
+	for _, test := range tests {
+		err := ValidateStrategy(FpeStrategy, test.dbType, test.params)
+		if (err == nil) != test.valid {
+			t.Errorf("%v %v: unexpected result %v", test.dbType, test.params, err)
+		}
+	}
+}
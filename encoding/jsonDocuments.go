@@ -0,0 +1,323 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	jsonPathRoot = "$"
+	emailDomain  = "example.com"
+)
+
+//strategies applicable to values matched by JSONPath
+var jsonStrategies = map[string]bool{
+	DefaultStrategy: true,
+	KeepStrategy:    true,
+	NullStrategy:    true,
+	HashStrategy:    true,
+	EmailStrategy:   true,
+	NoiseStrategy:   true,
+}
+
+type jsonStepKind int
+
+const (
+	keyStep        jsonStepKind = iota //.name or ['name']
+	indexStep                          //[0]
+	wildcardStep                       //.* or [*]
+	descendantStep                     //..name
+)
+
+type jsonStep struct {
+	kind  jsonStepKind
+	key   string
+	index int
+}
+
+//JSON strategy params are JSONPath -> strategy of matched values, e.g. "$.contacts[*].email": "email".
+//Null replaces matched value, other strategies are applied to all scalars inside it, not matched values are kept.
+//Paths are applied in sorted order
+func jsonValue(rawValue interface{}, dbType string, params map[string]string) (interface{}, error) {
+	if !IsJsonType(dbType) {
+		return nil, fmt.Errorf("json strategy isn't applicable to type %v", dbType)
+	}
+	document, err := decodeJson(rawValue)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for path := range params {
+		if !strings.HasPrefix(path, jsonPathRoot) {
+			return nil, fmt.Errorf("param %v isn't JSONPath", path)
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		steps, err := parseJsonPath(path)
+		if err != nil {
+			return nil, err
+		}
+		strategy := params[path]
+		document, err = applyJsonPath(document, steps, func(node interface{}) (interface{}, error) {
+			return obfuscateJsonNode(node, strategy)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("path %v: %v", path, err)
+		}
+	}
+	return encodeJson(document)
+}
+
+//all strings are hashed and all numbers are noised by default
+func obfuscateJson(rawValue interface{}) (interface{}, error) {
+	document, err := decodeJson(rawValue)
+	if err != nil {
+		return nil, err
+	}
+	document, err = obfuscateJsonNode(document, DefaultStrategy)
+	if err != nil {
+		return nil, err
+	}
+	return encodeJson(document)
+}
+
+func decodeJson(rawValue interface{}) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(asString(rawValue)))
+	//numbers are kept as is not to lose precision of big integers
+	decoder.UseNumber()
+	var document interface{}
+	err := decoder.Decode(&document)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	return document, nil
+}
+
+func encodeJson(document interface{}) (string, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(document)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
+
+//every path must be valid and every strategy applicable to JSON values
+func validateJsonParams(dbType string, params map[string]string) error {
+	if !IsJsonType(dbType) {
+		return fmt.Errorf("json strategy isn't applicable to type %v", dbType)
+	}
+	for path, strategy := range params {
+		if !strings.HasPrefix(path, jsonPathRoot) {
+			return fmt.Errorf("param %v isn't JSONPath", path)
+		}
+		if _, err := parseJsonPath(path); err != nil {
+			return fmt.Errorf("path %v: %v", path, err)
+		}
+		if !jsonStrategies[strategy] {
+			return fmt.Errorf("path %v: strategy %v isn't applicable to JSON values", path, strategy)
+		}
+	}
+	return nil
+}
+
+func obfuscateJsonNode(node interface{}, strategy string) (interface{}, error) {
+	if !jsonStrategies[strategy] {
+		return nil, fmt.Errorf("strategy %v isn't applicable to JSON values", strategy)
+	}
+	switch strategy {
+	case NullStrategy:
+		return nil, nil
+	case KeepStrategy:
+		return node, nil
+	}
+
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			obfuscatedChild, err := obfuscateJsonNode(child, strategy)
+			if err != nil {
+				return nil, err
+			}
+			value[key] = obfuscatedChild
+		}
+		return value, nil
+	case []interface{}:
+		for i, child := range value {
+			obfuscatedChild, err := obfuscateJsonNode(child, strategy)
+			if err != nil {
+				return nil, err
+			}
+			value[i] = obfuscatedChild
+		}
+		return value, nil
+	case string:
+		switch strategy {
+		case DefaultStrategy, HashStrategy:
+			return getMD5Hash(value), nil
+		case EmailStrategy:
+			return getFakeEmail(value), nil
+		}
+	case json.Number:
+		switch strategy {
+		case DefaultStrategy, NoiseStrategy:
+			return noiseJsonNumber(value)
+		case HashStrategy:
+			return getMD5Hash(value.String()), nil
+		}
+	}
+	//booleans and nulls are kept
+	return node, nil
+}
+
+func noiseJsonNumber(number json.Number) (json.Number, error) {
+	if _, err := strconv.ParseInt(number.String(), 10, 64); err == nil {
+		value, err := obfuscateInt(number.String(), BigintType)
+		if err != nil {
+			return "", err
+		}
+		return json.Number(strconv.FormatInt(value, 10)), nil
+	}
+	value, err := obfuscateFloat(number.String(), DoubleType)
+	if err != nil {
+		return "", err
+	}
+	return json.Number(strconv.FormatFloat(value, 'g', -1, 64)), nil
+}
+
+//the same values get the same emails, so joins by email still work
+func getFakeEmail(value string) string {
+	return getMD5Hash(value)[:12] + "@" + emailDomain
+}
+
+//supported subset: $, .name, ['name'], [0], .*, [*], ..name
+func parseJsonPath(path string) ([]jsonStep, error) {
+	if !strings.HasPrefix(path, jsonPathRoot) {
+		return nil, fmt.Errorf("JSONPath %v doesn't start with $", path)
+	}
+	var steps []jsonStep
+	rest := path[len(jsonPathRoot):]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			name, tail := readJsonPathName(rest[2:])
+			if name == "" || name == "*" {
+				return nil, fmt.Errorf("JSONPath %v: name is expected after ..", path)
+			}
+			steps = append(steps, jsonStep{kind: descendantStep, key: name})
+			rest = tail
+		case strings.HasPrefix(rest, "."):
+			name, tail := readJsonPathName(rest[1:])
+			if name == "" {
+				return nil, fmt.Errorf("JSONPath %v: name is expected after .", path)
+			}
+			if name == "*" {
+				steps = append(steps, jsonStep{kind: wildcardStep})
+			} else {
+				steps = append(steps, jsonStep{kind: keyStep, key: name})
+			}
+			rest = tail
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %v: ] is missed", path)
+			}
+			selector := rest[1:end]
+			rest = rest[end+1:]
+			if selector == "*" {
+				steps = append(steps, jsonStep{kind: wildcardStep})
+				continue
+			}
+			if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') &&
+				selector[len(selector)-1] == selector[0] {
+				steps = append(steps, jsonStep{kind: keyStep, key: selector[1 : len(selector)-1]})
+				continue
+			}
+			index, err := strconv.Atoi(selector)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("JSONPath %v: invalid selector [%v]", path, selector)
+			}
+			steps = append(steps, jsonStep{kind: indexStep, index: index})
+		default:
+			return nil, fmt.Errorf("JSONPath %v: unexpected %v", path, rest)
+		}
+	}
+	return steps, nil
+}
+
+func readJsonPathName(path string) (name string, rest string) {
+	end := strings.IndexAny(path, ".[")
+	if end < 0 {
+		return path, ""
+	}
+	return path[:end], path[end:]
+}
+
+//returns the node with transformed matched values, missing keys and indexes are skipped
+func applyJsonPath(node interface{}, steps []jsonStep, transform func(interface{}) (interface{}, error)) (interface{}, error) {
+	if len(steps) == 0 {
+		return transform(node)
+	}
+	step, rest := steps[0], steps[1:]
+	var err error
+	switch value := node.(type) {
+	case map[string]interface{}:
+		switch step.kind {
+		case keyStep:
+			if child, exists := value[step.key]; exists {
+				value[step.key], err = applyJsonPath(child, rest, transform)
+			}
+		case wildcardStep:
+			for key, child := range value {
+				value[key], err = applyJsonPath(child, rest, transform)
+				if err != nil {
+					break
+				}
+			}
+		case descendantStep:
+			for key, child := range value {
+				value[key], err = applyJsonPath(child, steps, transform)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if child, exists := value[step.key]; exists {
+				value[step.key], err = applyJsonPath(child, rest, transform)
+			}
+		}
+	case []interface{}:
+		switch step.kind {
+		case indexStep:
+			if step.index < len(value) {
+				value[step.index], err = applyJsonPath(value[step.index], rest, transform)
+			}
+		case wildcardStep:
+			for i, child := range value {
+				value[i], err = applyJsonPath(child, rest, transform)
+				if err != nil {
+					break
+				}
+			}
+		case descendantStep:
+			for i, child := range value {
+				value[i], err = applyJsonPath(child, steps, transform)
+				if err != nil {
+					break
+				}
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
@@ -0,0 +1,101 @@
+package encoding
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseJsonPath(t *testing.T) {
+	valid := []string{"$", "$.a", "$['a b']", "$.a[0].b", "$.*", "$[*]", "$..email", `$["x"].y`}
+	for _, path := range valid {
+		if _, err := parseJsonPath(path); err != nil {
+			t.Errorf("%v: %v", path, err)
+		}
+	}
+	invalid := []string{"a.b", "$.", "$..", "$..*", "$[", "$[-1]", "$[x]", "$a"}
+	for _, path := range invalid {
+		if _, err := parseJsonPath(path); err == nil {
+			t.Errorf("%v: error expected", path)
+		}
+	}
+}
+
+func TestJsonValue(t *testing.T) {
+	document := `{"name":"John","contacts":[{"email":"j@x.com","phone":"123"}],"age":30,"nested":{"email":"k@x.com"}}`
+	value, err := jsonValue([]byte(document), JsonType, map[string]string{
+		"$.contacts[*].email": EmailStrategy,
+		"$..phone":            NullStrategy,
+		"$.name":              HashStrategy,
+		"$.nested":            KeepStrategy,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result map[string]interface{}
+	err = json.Unmarshal([]byte(value.(string)), &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contact := result["contacts"].([]interface{})[0].(map[string]interface{})
+	if contact["email"] != getFakeEmail("j@x.com") {
+		t.Errorf("email isn't replaced: %v", contact["email"])
+	}
+	if contact["phone"] != nil {
+		t.Errorf("phone isn't nulled: %v", contact["phone"])
+	}
+	if result["name"] != getMD5Hash("John") {
+		t.Errorf("name isn't hashed: %v", result["name"])
+	}
+	if result["age"] != float64(30) {
+		t.Errorf("not matched value is changed: %v", result["age"])
+	}
+	if result["nested"].(map[string]interface{})["email"] != "k@x.com" {
+		t.Errorf("kept value is changed")
+	}
+
+	_, err = jsonValue([]byte(document), JsonType, map[string]string{"contacts.email": EmailStrategy})
+	if err == nil {
+		t.Error("param which isn't JSONPath must fail")
+	}
+}
+
+func TestObfuscateJson(t *testing.T) {
+	value, err := obfuscateJson([]byte(`{"a":"x","b":[1,true,null]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result map[string]interface{}
+	err = json.Unmarshal([]byte(value.(string)), &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["a"] != getMD5Hash("x") {
+		t.Errorf("string isn't hashed: %v", result["a"])
+	}
+	items := result["b"].([]interface{})
+	if items[1] != true || items[2] != nil {
+		t.Errorf("booleans and nulls must be kept: %v", items)
+	}
+}
+
+func TestValidateJsonParams(t *testing.T) {
+	tests := []struct {
+		dbType string
+		params map[string]string
+		valid  bool
+	}{
+		{JsonType, map[string]string{"$.a": EmailStrategy, "$..b": NullStrategy}, true},
+		{JsonType, nil, true},
+		{"text", map[string]string{"$.a": EmailStrategy}, false},
+		{JsonType, map[string]string{"customer.email": EmailStrategy}, false},
+		{JsonType, map[string]string{"$.a[": EmailStrategy}, false},
+		{JsonType, map[string]string{"$.a": MaskStrategy}, false},
+		{JsonType, map[string]string{"$.a": "emial"}, false},
+	}
+	for _, test := range tests {
+		err := ValidateStrategy(JsonStrategy, test.dbType, test.params)
+		if (err == nil) != test.valid {
+			t.Errorf("%v %v: unexpected result %v", test.dbType, test.params, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package encoding
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"strings"
+	"unicode"
+)
+
+var loremWords = strings.Fields(`lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod tempor
+	incididunt ut labore et dolore magna aliqua enim ad minim veniam quis nostrud exercitation ullamco laboris nisi
+	aliquip ex ea commodo consequat duis aute irure in reprehenderit voluptate velit esse cillum eu fugiat nulla
+	pariatur excepteur sint occaecat cupidatat non proident sunt culpa qui officia deserunt mollit anim id est
+	laborum a at vel nec per cum mus quam nunc orci odio arcu diam eros ante urna nibh erat justo massa proin morbi
+	fusce metus augue lacus risus felis purus neque mauris libero turpis sapien tellus dictum semper mattis cursus
+	viverra aliquam pretium egestas volutpat pulvinar sagittis interdum molestie tincidunt vulputate scelerisque
+	ultricies fringilla malesuada elementum venenatis phasellus curabitur vestibulum condimentum pellentesque
+	sollicitudin ullamcorper consectetuer`)
+
+//length -> lorem words of this length
+var loremWordsByLength = make(map[int][]string)
+
+func init() {
+	for _, word := range loremWords {
+		loremWordsByLength[len(word)] = append(loremWordsByLength[len(word)], word)
+	}
+}
+
+//words are replaced by lorem words of the same length keeping case of each letter, digits by random digits,
+//whitespaces and punctuation are kept, so the result has the same count of characters.
+//The result is the same for the same value
+func loremValue(rawValue interface{}, dbType string, _ map[string]string) (interface{}, error) {
+	if !IsStringType(dbType) {
+		return nil, fmt.Errorf("lorem strategy isn't applicable to type %v", dbType)
+	}
+	value := asString(rawValue)
+	hash := sha256.Sum256([]byte(value))
+	random := rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(hash[:8]))))
+
+	runes := []rune(value)
+	var sb strings.Builder
+	sb.Grow(len(value))
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsLetter(r):
+			end := i
+			for end < len(runes) && unicode.IsLetter(runes[end]) {
+				end++
+			}
+			for j, letter := range getLoremWord(end-i, random) {
+				if unicode.IsUpper(runes[i+j]) {
+					letter = unicode.ToUpper(letter)
+				}
+				sb.WriteRune(letter)
+			}
+			i = end
+		case unicode.IsDigit(r):
+			sb.WriteByte(byte('0' + random.Intn(10)))
+			i++
+		default:
+			sb.WriteRune(r)
+			i++
+		}
+	}
+	return sb.String(), nil
+}
+
+//words longer than the longest lorem word are glued from several words
+func getLoremWord(length int, random *rand.Rand) string {
+	if words := loremWordsByLength[length]; len(words) > 0 {
+		return words[random.Intn(len(words))]
+	}
+	var sb strings.Builder
+	for sb.Len() < length {
+		sb.WriteString(loremWords[random.Intn(len(loremWords))])
+	}
+	return sb.String()[:length]
+}
@@ -0,0 +1,68 @@
+package encoding
+
+import (
+	"math/rand"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+func TestLoremValue(t *testing.T) {
+	values := []string{"John Smith", "ANNA-Maria O'Neil, 42 Baker St.", "Иван Петров", "supercalifragilistic", ""}
+	for _, value := range values {
+		result, err := loremValue(value, TextType, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := result.(string)
+		again, _ := loremValue(value, TextType, nil)
+		if again != text {
+			t.Errorf("%v: result isn't deterministic, %v and %v", value, text, again)
+		}
+		runes, resultRunes := []rune(value), []rune(text)
+		if len(runes) != len(resultRunes) {
+			t.Fatalf("%v: length changed in %v", value, text)
+		}
+		for i, r := range runes {
+			switch {
+			case unicode.IsLetter(r):
+				if !unicode.IsLetter(resultRunes[i]) || unicode.IsUpper(r) != unicode.IsUpper(resultRunes[i]) {
+					t.Errorf("%v: letter %c is replaced by %c in %v", value, r, resultRunes[i], text)
+				}
+			case unicode.IsDigit(r):
+				if !unicode.IsDigit(resultRunes[i]) {
+					t.Errorf("%v: digit %c is replaced by %c in %v", value, r, resultRunes[i], text)
+				}
+			default:
+				if resultRunes[i] != r {
+					t.Errorf("%v: separator %c is replaced by %c in %v", value, r, resultRunes[i], text)
+				}
+			}
+		}
+		if value != "" && text == value {
+			t.Errorf("%v isn't changed", value)
+		}
+	}
+}
+
+func TestGetLoremWord(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for length := 1; length <= 40; length++ {
+		word := getLoremWord(length, random)
+		if utf8.RuneCountInString(word) != length {
+			t.Errorf("word %v hasn't length %v", word, length)
+		}
+	}
+}
+
+func TestValidateLoremParams(t *testing.T) {
+	if err := ValidateStrategy(LoremStrategy, "varchar(20)", nil); err != nil {
+		t.Error(err)
+	}
+	if err := ValidateStrategy(LoremStrategy, IntType, nil); err == nil {
+		t.Errorf("lorem strategy isn't applicable to integers")
+	}
+	if err := ValidateStrategy(LoremStrategy, TextType, map[string]string{"words": "5"}); err == nil {
+		t.Errorf("unknown param must be rejected")
+	}
+}
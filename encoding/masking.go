@@ -0,0 +1,158 @@
+package encoding
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+//MaskStrategy params
+const (
+	//count of first and last characters which aren't masked, separators aren't counted
+	KeepFirstParam = "keepFirst"
+	KeepLastParam  = "keepLast"
+	//"*" by default
+	MaskCharParam = "maskChar"
+	//characters other than letters and digits aren't masked, true by default
+	PreserveSeparatorsParam = "preserveSeparators"
+	//characters of capture groups aren't masked, the whole value is masked if it doesn't match
+	KeepRegexParam = "keepRegex"
+)
+
+const defaultMaskChar = '*'
+
+var (
+	//compiled once per pattern
+	maskRegexps      = make(map[string]*regexp.Regexp)
+	maskRegexpsMutex sync.Mutex
+)
+
+//e.g. keepLast=4 turns "4111-1111-1111-1234" into "****-****-****-1234"
+func maskValue(rawValue interface{}, dbType string, params map[string]string) (interface{}, error) {
+	if !IsStringType(dbType) {
+		return nil, fmt.Errorf("mask strategy isn't applicable to type %v", dbType)
+	}
+	keepFirst, err := getIntParam(params, KeepFirstParam, 0)
+	if err != nil {
+		return nil, err
+	}
+	keepLast, err := getIntParam(params, KeepLastParam, 0)
+	if err != nil {
+		return nil, err
+	}
+	if keepFirst < 0 || keepLast < 0 {
+		return nil, fmt.Errorf("params %v and %v can't be negative", KeepFirstParam, KeepLastParam)
+	}
+	maskChar := rune(defaultMaskChar)
+	if value, exists := params[MaskCharParam]; exists {
+		if utf8.RuneCountInString(value) != 1 {
+			return nil, fmt.Errorf("param %v must be one character", MaskCharParam)
+		}
+		maskChar, _ = utf8.DecodeRuneInString(value)
+	}
+	preserveSeparators := true
+	if value, exists := params[PreserveSeparatorsParam]; exists {
+		preserveSeparators, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid param %v: %v", PreserveSeparatorsParam, err)
+		}
+	}
+
+	value := asString(rawValue)
+	runes := []rune(value)
+	kept := make([]bool, len(runes))
+	if pattern := params[KeepRegexParam]; pattern != "" {
+		re, err := getMaskRegexp(pattern)
+		if err != nil {
+			return nil, err
+		}
+		markCapturedRunes(value, re, kept)
+	}
+
+	var maskable []int
+	for i, r := range runes {
+		if preserveSeparators && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			kept[i] = true
+		} else {
+			maskable = append(maskable, i)
+		}
+	}
+	for i, index := range maskable {
+		if int64(i) < keepFirst || int64(len(maskable)-i) <= keepLast {
+			kept[index] = true
+		}
+	}
+	//value which is shorter than kept characters or is captured by regexp is masked entirely, not revealed
+	isRevealed := true
+	for _, index := range maskable {
+		isRevealed = isRevealed && kept[index]
+	}
+	if isRevealed {
+		for _, index := range maskable {
+			kept[index] = false
+		}
+	}
+
+	for i := range runes {
+		if !kept[i] {
+			runes[i] = maskChar
+		}
+	}
+	result := string(runes)
+	if strings.HasPrefix(dbType, CharType) || strings.HasPrefix(dbType, VarcharType) {
+		size, err := strconv.Atoi(getSubstringInSingleLastBrackets(dbType))
+		if err != nil {
+			return nil, err
+		}
+		result = trimStr(result, size)
+	}
+	return result, nil
+}
+
+//marks characters of all capture groups of the first match
+func markCapturedRunes(value string, re *regexp.Regexp, kept []bool) {
+	match := re.FindStringSubmatchIndex(value)
+	if match == nil {
+		return
+	}
+	for group := 1; group*2 < len(match); group++ {
+		start, end := match[group*2], match[group*2+1]
+		if start < 0 {
+			continue
+		}
+		//byte offsets to rune indexes
+		first := utf8.RuneCountInString(value[:start])
+		count := utf8.RuneCountInString(value[start:end])
+		for i := first; i < first+count; i++ {
+			kept[i] = true
+		}
+	}
+}
+
+func getMaskRegexp(pattern string) (*regexp.Regexp, error) {
+	maskRegexpsMutex.Lock()
+	defer maskRegexpsMutex.Unlock()
+	if re, exists := maskRegexps[pattern]; exists {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid param %v: %v", KeepRegexParam, err)
+	}
+	maskRegexps[pattern] = re
+	return re, nil
+}
+
+func validateMaskParams(dbType string, params map[string]string) error {
+	err := checkParams(params, KeepFirstParam, KeepLastParam, MaskCharParam, PreserveSeparatorsParam, KeepRegexParam)
+	if err != nil {
+		return err
+	}
+	//the same parsing as for values
+	_, err = maskValue("", dbType, params)
+	return err
+}
@@ -0,0 +1,80 @@
+package encoding
+
+import "testing"
+
+func TestMaskValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		dbType   string
+		params   map[string]string
+		expected string
+	}{
+		{"4111-1111-1111-1234", "varchar(19)", map[string]string{KeepLastParam: "4"}, "****-****-****-1234"},
+		{"4111-1111-1111-1234", "varchar(19)", map[string]string{KeepFirstParam: "1", KeepLastParam: "2",
+			PreserveSeparatorsParam: "false"}, "4****************34"},
+		{"John Smith", TextType, map[string]string{KeepFirstParam: "1", MaskCharParam: "#"}, "J### #####"},
+		{"Иван", TextType, map[string]string{KeepLastParam: "1"}, "***н"},
+		{"john@example.com", TextType, map[string]string{KeepRegexParam: "(@.*)$"}, "****@example.com"},
+		{"no-at-sign", TextType, map[string]string{KeepRegexParam: "(@.*)$"}, "**-**-****"},
+		{"4111-1111", "varchar(4)", nil, "****"},
+		{"", TextType, map[string]string{KeepLastParam: "4"}, ""},
+		{"--", TextType, nil, "--"},
+	}
+	for _, test := range tests {
+		value, err := maskValue(test.value, test.dbType, test.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != test.expected {
+			t.Errorf("%v %v: got %v, expected %v", test.value, test.params, value, test.expected)
+		}
+	}
+}
+
+//kept characters mustn't reveal the whole value
+func TestMaskValueShort(t *testing.T) {
+	tests := []struct {
+		value    string
+		params   map[string]string
+		expected string
+	}{
+		{"1234", map[string]string{KeepLastParam: "4"}, "****"},
+		{"12-34", map[string]string{KeepFirstParam: "2", KeepLastParam: "2"}, "**-**"},
+		{"123", map[string]string{KeepFirstParam: "2", KeepLastParam: "2"}, "***"},
+		{"12345", map[string]string{KeepFirstParam: "2", KeepLastParam: "2"}, "12*45"},
+		{"secret", map[string]string{KeepRegexParam: "(.*)"}, "******"},
+	}
+	for _, test := range tests {
+		value, err := maskValue(test.value, TextType, test.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != test.expected {
+			t.Errorf("%v %v: got %v, expected %v", test.value, test.params, value, test.expected)
+		}
+	}
+}
+
+func TestValidateMaskParams(t *testing.T) {
+	tests := []struct {
+		dbType string
+		params map[string]string
+		valid  bool
+	}{
+		{"varchar(10)", map[string]string{KeepFirstParam: "1", KeepLastParam: "2", MaskCharParam: "x"}, true},
+		{TextType, map[string]string{KeepRegexParam: "^(\\d{3})", PreserveSeparatorsParam: "false"}, true},
+		{IntType, nil, false},
+		{TextType, map[string]string{KeepLastParam: "-1"}, false},
+		{TextType, map[string]string{KeepFirstParam: "one"}, false},
+		{TextType, map[string]string{MaskCharParam: "**"}, false},
+		{TextType, map[string]string{PreserveSeparatorsParam: "maybe"}, false},
+		{TextType, map[string]string{KeepRegexParam: "("}, false},
+		{TextType, map[string]string{"keep": "1"}, false},
+	}
+	for _, test := range tests {
+		err := ValidateStrategy(MaskStrategy, test.dbType, test.params)
+		if (err == nil) != test.valid {
+			t.Errorf("%v %v: unexpected result %v", test.dbType, test.params, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package encoding
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net"
+)
+
+//IpStrategy params, ModeParam is truncate (default) or map
+const (
+	//prefixes kept by truncate mode, 24 and 48 by default
+	Ipv4PrefixParam = "ipv4Prefix"
+	Ipv6PrefixParam = "ipv6Prefix"
+)
+
+const (
+	TruncateIpMode = "truncate"
+	MapIpMode      = "map"
+
+	defaultIpv4Prefix = 24
+	defaultIpv6Prefix = 48
+)
+
+var (
+	//addresses are mapped to benchmarking (RFC 2544) and documentation (RFC 3849) ranges
+	ipv4MapNetwork = mustParseCidr("198.18.0.0/15")
+	ipv6MapNetwork = mustParseCidr("2001:db8::/32")
+)
+
+//text columns keep text form, binary columns keep INET6_ATON form of 4 or 16 bytes
+func ipValue(rawValue interface{}, dbType string, params map[string]string) (interface{}, error) {
+	if !IsStringType(dbType) && !IsBinaryType(dbType) {
+		return nil, fmt.Errorf("ip strategy isn't applicable to type %v", dbType)
+	}
+
+	var ip net.IP
+	if IsBinaryType(dbType) {
+		value, ok := rawValue.([]byte)
+		if !ok || (len(value) != net.IPv4len && len(value) != net.IPv6len) {
+			return nil, fmt.Errorf("value isn't binary IP address")
+		}
+		ip = net.IP(append([]byte{}, value...))
+	} else {
+		ip = net.ParseIP(asString(rawValue))
+		if ip == nil {
+			return nil, fmt.Errorf("value isn't IP address")
+		}
+	}
+	isIpv4 := ip.To4() != nil
+	if isIpv4 {
+		ip = ip.To4()
+	}
+
+	var result net.IP
+	switch params[ModeParam] {
+	case "", TruncateIpMode:
+		prefixParam, defaultPrefix, bits := Ipv6PrefixParam, int64(defaultIpv6Prefix), 128
+		if isIpv4 {
+			prefixParam, defaultPrefix, bits = Ipv4PrefixParam, defaultIpv4Prefix, 32
+		}
+		prefix, err := getIntParam(params, prefixParam, defaultPrefix)
+		if err != nil {
+			return nil, err
+		}
+		if prefix < 0 || prefix > int64(bits) {
+			return nil, fmt.Errorf("invalid param %v: %v", prefixParam, prefix)
+		}
+		result = ip.Mask(net.CIDRMask(int(prefix), bits))
+	case MapIpMode:
+		network := ipv6MapNetwork
+		if isIpv4 {
+			network = ipv4MapNetwork
+		}
+		result = mapIp(ip, network)
+	default:
+		return nil, fmt.Errorf("unknown mode %v", params[ModeParam])
+	}
+
+	if IsBinaryType(dbType) {
+		//IPv4-mapped IPv6 addresses keep their length
+		if len(rawValue.([]byte)) == net.IPv6len {
+			result = result.To16()
+		}
+		return []byte(result), nil
+	}
+	return result.String(), nil
+}
+
+func validateIpParams(dbType string, params map[string]string) error {
+	if !IsStringType(dbType) && !IsBinaryType(dbType) {
+		return fmt.Errorf("ip strategy isn't applicable to type %v", dbType)
+	}
+	err := checkParams(params, ModeParam, Ipv4PrefixParam, Ipv6PrefixParam)
+	if err != nil {
+		return err
+	}
+	switch params[ModeParam] {
+	case "", TruncateIpMode, MapIpMode:
+	default:
+		return fmt.Errorf("unknown mode %v", params[ModeParam])
+	}
+	prefixes := []struct {
+		param         string
+		defaultPrefix int64
+		bits          int64
+	}{
+		{Ipv4PrefixParam, defaultIpv4Prefix, 32},
+		{Ipv6PrefixParam, defaultIpv6Prefix, 128},
+	}
+	for _, prefix := range prefixes {
+		value, err := getIntParam(params, prefix.param, prefix.defaultPrefix)
+		if err != nil {
+			return err
+		}
+		if value < 0 || value > prefix.bits {
+			return fmt.Errorf("invalid param %v: %v", prefix.param, value)
+		}
+	}
+	return nil
+}
+
+//host bits of the network are taken from hash of the address, so the same addresses get the same results
+func mapIp(ip net.IP, network *net.IPNet) net.IP {
+	hash := sha256.Sum256(ip)
+	result := make(net.IP, len(network.IP))
+	for i := range result {
+		result[i] = network.IP[i] | (hash[i] &^ network.Mask[i])
+	}
+	return result
+}
+
+func mustParseCidr(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
@@ -0,0 +1,85 @@
+package encoding
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+func TestIpValueTruncate(t *testing.T) {
+	tests := []struct {
+		value    string
+		params   map[string]string
+		expected string
+	}{
+		{"192.168.10.77", nil, "192.168.10.0"},
+		{"192.168.10.77", map[string]string{Ipv4PrefixParam: "16"}, "192.168.0.0"},
+		{"2001:db8:1234:5678::1", nil, "2001:db8:1234::"},
+		{"2001:db8:1234:5678::1", map[string]string{Ipv6PrefixParam: "64"}, "2001:db8:1234:5678::"},
+	}
+	for _, test := range tests {
+		value, err := ipValue(test.value, "varchar(45)", test.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != test.expected {
+			t.Errorf("%v %v: got %v, expected %v", test.value, test.params, value, test.expected)
+		}
+	}
+}
+
+func TestIpValueMap(t *testing.T) {
+	params := map[string]string{ModeParam: MapIpMode}
+	first, err := ipValue("10.1.2.3", "varchar(45)", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ipValue("10.1.2.3", "varchar(45)", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second || !ipv4MapNetwork.Contains(net.ParseIP(first.(string))) {
+		t.Errorf("unexpected mapped address %v, %v", first, second)
+	}
+
+	value, err := ipValue("2001:abcd::1", "varchar(45)", params)
+	if err != nil || !ipv6MapNetwork.Contains(net.ParseIP(value.(string))) {
+		t.Errorf("unexpected mapped address %v, %v", value, err)
+	}
+}
+
+func TestIpValueBinary(t *testing.T) {
+	value, err := ipValue([]byte{10, 1, 2, 3}, "varbinary(16)", nil)
+	if err != nil || !bytes.Equal(value.([]byte), []byte{10, 1, 2, 0}) {
+		t.Errorf("unexpected result %v, %v", value, err)
+	}
+	mapped := []byte(net.ParseIP("10.1.2.3").To16())
+	value, err = ipValue(mapped, "varbinary(16)", nil)
+	if err != nil || len(value.([]byte)) != net.IPv6len {
+		t.Errorf("IPv4-mapped address must keep its length: %v, %v", value, err)
+	}
+	if _, err = ipValue([]byte{1, 2, 3}, "varbinary(16)", nil); err == nil {
+		t.Error("value of wrong length must fail")
+	}
+}
+
+func TestValidateIpParams(t *testing.T) {
+	tests := []struct {
+		dbType string
+		params map[string]string
+		valid  bool
+	}{
+		{"varchar(45)", map[string]string{ModeParam: MapIpMode}, true},
+		{"varbinary(16)", map[string]string{Ipv4PrefixParam: "8", Ipv6PrefixParam: "32"}, true},
+		{"int", nil, false},
+		{"varchar(45)", map[string]string{ModeParam: "hash"}, false},
+		{"varchar(45)", map[string]string{Ipv4PrefixParam: "33"}, false},
+		{"varchar(45)", map[string]string{"prefix": "24"}, false},
+	}
+	for _, test := range tests {
+		err := ValidateStrategy(IpStrategy, test.dbType, test.params)
+		if (err == nil) != test.valid {
+			t.Errorf("%v %v: unexpected result %v", test.dbType, test.params, err)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package encoding
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+//QuantileStrategy, RoundStrategy and LaplaceStrategy params, ModeParam of RoundStrategy is nearest (default),
+//floor or midpoint of the bucket
+const (
+	//QuantileStrategy: count of equal-frequency buckets, 20 by default
+	BucketsParam = "buckets"
+	//QuantileStrategy: JSON array of bucket edges, collected from origin automatically
+	HistogramParam = "histogram"
+	//RoundStrategy: values are rounded to multiple of step, e.g. 1000 or 0.5
+	StepParam = "step"
+	//LaplaceStrategy: privacy budget, 1 by default, smaller is more private
+	EpsilonParam = "epsilon"
+	//LaplaceStrategy: max difference one record makes, 1 by default
+	SensitivityParam = "sensitivity"
+)
+
+const (
+	NearestRoundMode  = "nearest"
+	FloorRoundMode    = "floor"
+	MidpointRoundMode = "midpoint"
+
+	DefaultBuckets = 20
+)
+
+//value is replaced by a random one from random bucket of origin histogram,
+//so the distribution of the column is kept while values aren't bound to rows
+func quantileValue(_ interface{}, dbType string, params map[string]string) (interface{}, error) {
+	if !IsNumericType(dbType) {
+		return nil, fmt.Errorf("quantile strategy isn't applicable to type %v", dbType)
+	}
+	if params[HistogramParam] == "" {
+		return nil, fmt.Errorf("histogram of column values isn't collected")
+	}
+	var edges []float64
+	err := json.Unmarshal([]byte(params[HistogramParam]), &edges)
+	if err != nil {
+		return nil, fmt.Errorf("invalid histogram: %v", err)
+	}
+	if len(edges) == 0 {
+		return nil, fmt.Errorf("histogram is empty")
+	}
+	if len(edges) == 1 {
+		return fitNumericType(edges[0], dbType)
+	}
+	bucket := rand.Intn(len(edges) - 1)
+	value := edges[bucket] + rand.Float64()*(edges[bucket+1]-edges[bucket])
+	return fitNumericType(value, dbType)
+}
+
+func roundValue(rawValue interface{}, dbType string, params map[string]string) (interface{}, error) {
+	if !IsNumericType(dbType) {
+		return nil, fmt.Errorf("round strategy isn't applicable to type %v", dbType)
+	}
+	value, err := strconv.ParseFloat(asString(rawValue), 64)
+	if err != nil {
+		return nil, err
+	}
+	step, err := getFloatParam(params, StepParam, 0)
+	if err != nil || step <= 0 {
+		return nil, fmt.Errorf("param %v must be positive number", StepParam)
+	}
+
+	switch params[ModeParam] {
+	case "", NearestRoundMode:
+		value = math.Round(value/step) * step
+	case FloorRoundMode:
+		value = math.Floor(value/step) * step
+	case MidpointRoundMode:
+		value = (math.Floor(value/step) + 0.5) * step
+	default:
+		return nil, fmt.Errorf("unknown mode %v", params[ModeParam])
+	}
+	return fitNumericType(value, dbType)
+}
+
+//noise of Laplace distribution with scale sensitivity/epsilon
+func laplaceValue(rawValue interface{}, dbType string, params map[string]string) (interface{}, error) {
+	if !IsNumericType(dbType) {
+		return nil, fmt.Errorf("laplace strategy isn't applicable to type %v", dbType)
+	}
+	value, err := strconv.ParseFloat(asString(rawValue), 64)
+	if err != nil {
+		return nil, err
+	}
+	epsilon, err := getFloatParam(params, EpsilonParam, 1)
+	if err != nil {
+		return nil, err
+	}
+	sensitivity, err := getFloatParam(params, SensitivityParam, 1)
+	if err != nil {
+		return nil, err
+	}
+	if epsilon <= 0 || sensitivity <= 0 {
+		return nil, fmt.Errorf("epsilon and sensitivity must be positive")
+	}
+
+	//inverse of CDF of uniform value in (-1/2, 1/2)
+	u := rand.Float64() - 0.5
+	for u == -0.5 {
+		u = rand.Float64() - 0.5
+	}
+	scale := sensitivity / epsilon
+	sign := 1.0
+	if u < 0 {
+		sign = -1
+	}
+	value -= scale * sign * math.Log(1-2*math.Abs(u))
+	return fitNumericType(value, dbType)
+}
+
+func validateQuantileParams(dbType string, params map[string]string) error {
+	if !IsNumericType(dbType) {
+		return fmt.Errorf("quantile strategy isn't applicable to type %v", dbType)
+	}
+	err := checkParams(params, BucketsParam, HistogramParam)
+	if err != nil {
+		return err
+	}
+	buckets, err := getIntParam(params, BucketsParam, DefaultBuckets)
+	if err != nil {
+		return err
+	}
+	if buckets <= 0 {
+		return fmt.Errorf("param %v must be positive integer", BucketsParam)
+	}
+	//histogram is usually collected from origin after validation
+	if params[HistogramParam] == "" {
+		return nil
+	}
+	var edges []float64
+	err = json.Unmarshal([]byte(params[HistogramParam]), &edges)
+	if err != nil {
+		return fmt.Errorf("invalid histogram: %v", err)
+	}
+	if len(edges) == 0 {
+		return fmt.Errorf("histogram is empty")
+	}
+	for i := 1; i < len(edges); i++ {
+		if edges[i] < edges[i-1] {
+			return fmt.Errorf("edges of histogram must be sorted")
+		}
+	}
+	return nil
+}
+
+func validateRoundParams(dbType string, params map[string]string) error {
+	if !IsNumericType(dbType) {
+		return fmt.Errorf("round strategy isn't applicable to type %v", dbType)
+	}
+	err := checkParams(params, StepParam, ModeParam)
+	if err != nil {
+		return err
+	}
+	step, err := getFloatParam(params, StepParam, 0)
+	if err != nil || step <= 0 {
+		return fmt.Errorf("param %v must be positive number", StepParam)
+	}
+	switch params[ModeParam] {
+	case "", NearestRoundMode, FloorRoundMode, MidpointRoundMode:
+		return nil
+	default:
+		return fmt.Errorf("unknown mode %v", params[ModeParam])
+	}
+}
+
+func validateLaplaceParams(dbType string, params map[string]string) error {
+	if !IsNumericType(dbType) {
+		return fmt.Errorf("laplace strategy isn't applicable to type %v", dbType)
+	}
+	err := checkParams(params, EpsilonParam, SensitivityParam)
+	if err != nil {
+		return err
+	}
+	epsilon, err := getFloatParam(params, EpsilonParam, 1)
+	if err != nil {
+		return err
+	}
+	sensitivity, err := getFloatParam(params, SensitivityParam, 1)
+	if err != nil {
+		return err
+	}
+	if epsilon <= 0 || sensitivity <= 0 {
+		return fmt.Errorf("epsilon and sensitivity must be positive")
+	}
+	return nil
+}
+
+//rounds integers and decimals and clamps the value to bounds of the type
+func fitNumericType(value float64, dbType string) (interface{}, error) {
+	switch {
+	case IsIntType(dbType):
+		lowerBound, upperBound := getIntBounds(dbType)
+		value = math.Round(value)
+		if value <= float64(lowerBound) {
+			return lowerBound, nil
+		}
+		if value >= float64(upperBound) {
+			return upperBound, nil
+		}
+		return int64(value), nil
+	case IsUintType(dbType):
+		upperBound := getUintUpperBound(dbType)
+		value = math.Round(value)
+		if value <= 0 {
+			return uint64(0), nil
+		}
+		if value >= float64(upperBound) {
+			return upperBound, nil
+		}
+		return uint64(value), nil
+	case strings.HasPrefix(dbType, DecimalType):
+		bound, err := getDecimalAbsBound(dbType)
+		if err != nil {
+			return nil, err
+		}
+		scale, err := getDecimalScale(dbType)
+		if err != nil {
+			return nil, err
+		}
+		//max value is 10^(precision-scale) - 10^-scale
+		maxValue := bound - math.Pow10(-scale)
+		value = math.Max(-maxValue, math.Min(maxValue, value))
+		return strconv.FormatFloat(value, 'f', scale, 64), nil
+	default:
+		return value, nil
+	}
+}
+
+func getIntBounds(dbType string) (int64, int64) {
+	switch dbType {
+	case TinyintType:
+		return LowerBoundTinyint, UpperBoundTinyint
+	case SmallintType:
+		return LowerBoundSmallint, UpperBoundSmallint
+	case MediumintType:
+		return LowerBoundMediumint, UpperBoundMediumint
+	case IntType:
+		return LowerBoundInt, UpperBoundInt
+	default:
+		return LowerBoundBigint, UpperBoundBigint
+	}
+}
+
+func getUintUpperBound(dbType string) uint64 {
+	switch dbType {
+	case UTinyintType:
+		return UpperBoundUTinyint
+	case USmallintType:
+		return UpperBoundUSmallint
+	case UMediumintType:
+		return UpperBoundUMediumint
+	case UIntType:
+		return UpperBoundUInt
+	default:
+		return UpperBoundUBigint
+	}
+}
+
+func getDecimalScale(dbType string) (int, error) {
+	sizes := strings.Split(getSubstringInSingleLastBrackets(dbType), ",")
+	if len(sizes) < 2 {
+		return 0, nil
+	}
+	return strconv.Atoi(strings.TrimSpace(sizes[1]))
+}
+
+func getFloatParam(params map[string]string, name string, defaultValue float64) (float64, error) {
+	rawValue, exists := params[name]
+	if !exists || rawValue == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.ParseFloat(rawValue, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid param %v: %v", name, err)
+	}
+	//NaN and infinities are parsed too, NaN passes any comparison with bounds
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("param %v must be finite number", name)
+	}
+	return value, nil
+}
@@ -0,0 +1,126 @@
+package encoding
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQuantileValue(t *testing.T) {
+	params := map[string]string{HistogramParam: "[10, 20, 50, 100]"}
+	for i := 0; i < 100; i++ {
+		value, err := quantileValue(nil, IntType, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if number := value.(int64); number < 10 || number > 100 {
+			t.Errorf("value %v is out of histogram", number)
+		}
+	}
+	value, err := quantileValue(nil, UTinyintType, map[string]string{HistogramParam: "[300]"})
+	if err != nil || value != uint64(UpperBoundUTinyint) {
+		t.Errorf("single edge must be clamped to the type, got %v, %v", value, err)
+	}
+	if _, err = quantileValue(nil, IntType, nil); err == nil {
+		t.Errorf("value without histogram must fail")
+	}
+}
+
+func TestRoundValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		dbType   string
+		params   map[string]string
+		expected interface{}
+	}{
+		{"1499", IntType, map[string]string{StepParam: "1000"}, int64(1000)},
+		{"1500", IntType, map[string]string{StepParam: "1000"}, int64(2000)},
+		{"1999", IntType, map[string]string{StepParam: "1000", ModeParam: FloorRoundMode}, int64(1000)},
+		{"1999", IntType, map[string]string{StepParam: "1000", ModeParam: MidpointRoundMode}, int64(1500)},
+		{"12.34", "decimal(5,2)", map[string]string{StepParam: "0.5"}, "12.50"},
+		{"999.99", "decimal(5,2)", map[string]string{StepParam: "10"}, "999.99"},
+		{"126", TinyintType, map[string]string{StepParam: "50"}, int64(UpperBoundTinyint)},
+		{"2.6", DoubleType, map[string]string{StepParam: "0.5"}, 2.5},
+	}
+	for _, test := range tests {
+		value, err := roundValue(test.value, test.dbType, test.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != test.expected {
+			t.Errorf("%v %v %v: got %v, expected %v", test.value, test.dbType, test.params, value, test.expected)
+		}
+	}
+}
+
+func TestRoundValueInvalidStep(t *testing.T) {
+	for _, step := range []string{"", "0", "NaN", "Inf", "-Inf"} {
+		if _, err := roundValue("10", IntType, map[string]string{StepParam: step}); err == nil {
+			t.Errorf("step %q must fail", step)
+		}
+	}
+}
+
+func TestLaplaceValue(t *testing.T) {
+	params := map[string]string{EpsilonParam: "0.5", SensitivityParam: "2"}
+	sum := 0.0
+	count := 10000
+	for i := 0; i < count; i++ {
+		value, err := laplaceValue("100", DoubleType, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum += value.(float64)
+	}
+	//mean of noise is 0, standard deviation of the mean is sqrt(2)*scale/sqrt(count) = 0.057
+	if mean := sum / float64(count); math.Abs(mean-100) > 0.5 {
+		t.Errorf("mean of noised values is %v", mean)
+	}
+	if _, err := laplaceValue("100", DoubleType, map[string]string{EpsilonParam: "NaN"}); err == nil {
+		t.Errorf("NaN epsilon must fail")
+	}
+	value, err := laplaceValue("0", UIntType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := value.(uint64); !ok {
+		t.Errorf("unsigned value is %T", value)
+	}
+}
+
+func TestValidateNumericStrategies(t *testing.T) {
+	tests := []struct {
+		strategy string
+		dbType   string
+		params   map[string]string
+		valid    bool
+	}{
+		{QuantileStrategy, IntType, nil, true},
+		{QuantileStrategy, DoubleType, map[string]string{BucketsParam: "10", HistogramParam: "[1, 2, 2, 5]"}, true},
+		{QuantileStrategy, TextType, nil, false},
+		{QuantileStrategy, IntType, map[string]string{BucketsParam: "0"}, false},
+		{QuantileStrategy, IntType, map[string]string{HistogramParam: "[]"}, false},
+		{QuantileStrategy, IntType, map[string]string{HistogramParam: "[5, 1]"}, false},
+		{QuantileStrategy, IntType, map[string]string{HistogramParam: "1, 2"}, false},
+		{RoundStrategy, IntType, map[string]string{StepParam: "1000", ModeParam: FloorRoundMode}, true},
+		{RoundStrategy, IntType, nil, false},
+		{RoundStrategy, IntType, map[string]string{StepParam: "-1"}, false},
+		{RoundStrategy, IntType, map[string]string{StepParam: "10", ModeParam: "ceil"}, false},
+		{RoundStrategy, IntType, map[string]string{StepParam: "10", EpsilonParam: "1"}, false},
+		{RoundStrategy, IntType, map[string]string{StepParam: "NaN"}, false},
+		{RoundStrategy, IntType, map[string]string{StepParam: "+Inf"}, false},
+		{LaplaceStrategy, "decimal(10,2)", map[string]string{EpsilonParam: "0.1", SensitivityParam: "100"}, true},
+		{LaplaceStrategy, IntType, nil, true},
+		{LaplaceStrategy, TextType, nil, false},
+		{LaplaceStrategy, IntType, map[string]string{EpsilonParam: "0"}, false},
+		{LaplaceStrategy, IntType, map[string]string{SensitivityParam: "x"}, false},
+		{LaplaceStrategy, IntType, map[string]string{EpsilonParam: "nan"}, false},
+		{LaplaceStrategy, IntType, map[string]string{EpsilonParam: "Inf"}, false},
+		{LaplaceStrategy, IntType, map[string]string{SensitivityParam: "infinity"}, false},
+	}
+	for _, test := range tests {
+		err := ValidateStrategy(test.strategy, test.dbType, test.params)
+		if (err == nil) != test.valid {
+			t.Errorf("%v %v %v: unexpected result %v", test.strategy, test.dbType, test.params, err)
+		}
+	}
+}
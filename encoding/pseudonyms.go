@@ -0,0 +1,100 @@
+package encoding
+
+import (
+	"fmt"
+	"obfuscator/vault"
+	"strconv"
+)
+
+//VaultStrategy params, other params are passed to the strategy generating pseudonyms
+const (
+	//values of the same domain get the same pseudonyms in all schemas and runs, e.g. customer_email
+	DomainParam = "domain"
+	//generates new pseudonyms, default by type if empty
+	PseudonymStrategyParam = "strategy"
+)
+
+//strategies which can't generate unique pseudonyms, e.g. masked or rounded values of many originals are the same
+var notPseudonymStrategies = map[string]bool{
+	KeepStrategy:        true,
+	NullStrategy:        true,
+	VaultStrategy:       true,
+	ShuffleStrategy:     true,
+	TemplateStrategy:    true,
+	TupleStrategy:       true,
+	MaskStrategy:        true,
+	RoundStrategy:       true,
+	TruncateStrategy:    true,
+	PlaceholderStrategy: true,
+	IpStrategy:          true,
+}
+
+//strategies generating the same result from the same value, so colliding pseudonym is generated again
+//from the value with the number of the attempt
+var saltedPseudonymStrategies = map[string]bool{
+	DefaultStrategy: true,
+	HashStrategy:    true,
+	EmailStrategy:   true,
+	LoremStrategy:   true,
+}
+
+func vaultValue(rawValue interface{}, dbType string, params map[string]string) (interface{}, error) {
+	strategy := params[PseudonymStrategyParam]
+	if notPseudonymStrategies[strategy] {
+		return nil, fmt.Errorf("strategy %v can't generate pseudonyms", strategy)
+	}
+	generate, exists := strategies[strategy]
+	if !exists {
+		return nil, fmt.Errorf("unknown strategy: %v", strategy)
+	}
+	return vault.GetOrCreate(params[DomainParam], asString(rawValue),
+		getPseudonymGenerator(rawValue, dbType, strategy, generate, params))
+}
+
+func getPseudonymGenerator(rawValue interface{}, dbType, strategy string, generate StrategyFunc,
+	params map[string]string) func(attempt int) (string, error) {
+	original := asString(rawValue)
+	return func(attempt int) (string, error) {
+		value := rawValue
+		isSalted := attempt > 0 && saltedPseudonymStrategies[strategy] && IsStringType(dbType)
+		if isSalted {
+			value = original + " " + strconv.Itoa(attempt)
+		}
+		pseudonym, err := generate(value, dbType, params)
+		if err != nil {
+			return "", err
+		}
+		if pseudonym == nil {
+			return "", fmt.Errorf("strategy %v generated NULL pseudonym", strategy)
+		}
+		result := asString(pseudonym)
+		//lorem text keeps the structure of the value, so the salt is cut off
+		if isSalted && strategy == LoremStrategy {
+			result = string([]rune(result)[:len([]rune(original))])
+		}
+		return result, nil
+	}
+}
+
+//new pseudonyms are written to the vault once per slice, before the slice is written to destination
+func FlushPseudonyms() error {
+	return vault.Flush()
+}
+
+func validateVaultParams(dbType string, params map[string]string) error {
+	if params[DomainParam] == "" {
+		return fmt.Errorf("param %v is required", DomainParam)
+	}
+	strategy := params[PseudonymStrategyParam]
+	if notPseudonymStrategies[strategy] {
+		return fmt.Errorf("strategy %v can't generate pseudonyms", strategy)
+	}
+	//other params belong to the strategy generating pseudonyms
+	strategyParams := make(map[string]string)
+	for name, value := range params {
+		if name != DomainParam && name != PseudonymStrategyParam {
+			strategyParams[name] = value
+		}
+	}
+	return ValidateStrategy(strategy, dbType, strategyParams)
+}
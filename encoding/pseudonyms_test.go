@@ -0,0 +1,69 @@
+package encoding
+
+import "testing"
+
+//deterministic strategies generate another pseudonym of the same format on every attempt
+func TestPseudonymGeneratorSalt(t *testing.T) {
+	tests := []struct {
+		strategy string
+		value    string
+		dbType   string
+	}{
+		{DefaultStrategy, "John Smith", "varchar(20)"},
+		{HashStrategy, "John Smith", TextType},
+		{EmailStrategy, "john@example.com", "varchar(40)"},
+		{LoremStrategy, "John Smith, 42", "varchar(14)"},
+	}
+	for _, test := range tests {
+		generate := getPseudonymGenerator(test.value, test.dbType, test.strategy, strategies[test.strategy], nil)
+		first, err := generate(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := generate(0)
+		if err != nil || again != first {
+			t.Errorf("%v: the first attempt must be deterministic, got %v and %v, %v", test.strategy, first, again, err)
+		}
+		results := map[string]bool{first: true}
+		for attempt := 1; attempt < 5; attempt++ {
+			pseudonym, err := generate(attempt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len([]rune(pseudonym)) != len([]rune(first)) {
+				t.Errorf("%v: attempt %v changed format %v to %v", test.strategy, attempt, first, pseudonym)
+			}
+			results[pseudonym] = true
+		}
+		if len(results) != 5 {
+			t.Errorf("%v: attempts generated the same pseudonyms %v", test.strategy, results)
+		}
+	}
+}
+
+func TestValidateVaultParams(t *testing.T) {
+	tests := []struct {
+		dbType string
+		params map[string]string
+		valid  bool
+	}{
+		{"varchar(20)", map[string]string{DomainParam: "customer"}, true},
+		{"varchar(20)", map[string]string{DomainParam: "customer", PseudonymStrategyParam: LoremStrategy}, true},
+		{IntType, map[string]string{DomainParam: "customer", PseudonymStrategyParam: FpeStrategy,
+			TweakParam: "id"}, true},
+		{"varchar(20)", nil, false},
+		{"varchar(20)", map[string]string{DomainParam: "customer", PseudonymStrategyParam: MaskStrategy}, false},
+		{IntType, map[string]string{DomainParam: "customer", PseudonymStrategyParam: RoundStrategy,
+			StepParam: "10"}, false},
+		{"varchar(20)", map[string]string{DomainParam: "customer", PseudonymStrategyParam: "unknown"}, false},
+		{"varchar(20)", map[string]string{DomainParam: "customer", PseudonymStrategyParam: HashStrategy,
+			StepParam: "10"}, false},
+		{IntType, map[string]string{DomainParam: "customer", PseudonymStrategyParam: HashStrategy}, false},
+	}
+	for _, test := range tests {
+		err := ValidateStrategy(VaultStrategy, test.dbType, test.params)
+		if (err == nil) != test.valid {
+			t.Errorf("%v %v: unexpected result %v", test.dbType, test.params, err)
+		}
+	}
+}
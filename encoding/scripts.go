@@ -0,0 +1,299 @@
+package encoding
+
+import (
+	"crypto/md5"
+	"fmt"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+	"math/big"
+	"obfuscator/config"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+//ScriptStrategy params
+const (
+	//Starlark expression of value (current value) and row (dict of original values of the row),
+	//e.g. "mask(value, keepLast=4) if row['country'] == 'US' else hash(value)"
+	ScriptParam = "script"
+)
+
+const (
+	//scripts are stopped after the step limit or timeout from config
+	maxScriptSteps       = 1000000
+	defaultScriptTimeout = 100 * time.Millisecond
+	scriptFunctionName   = "transform"
+)
+
+var (
+	//compiled once per script
+	scripts      = make(map[string]*starlark.Function)
+	scriptsMutex sync.Mutex
+)
+
+//functions available in scripts, there is no access to files, network and clock
+var scriptBuiltins = starlark.StringDict{
+	"hash": starlark.NewBuiltin("hash", scriptHash),
+	"mask": starlark.NewBuiltin("mask", scriptMask),
+	"fake": starlark.NewBuiltin("fake", scriptFake),
+}
+
+//kind -> generator of fake value deterministic by seed, see fake(kind, seed)
+var fakeGenerators = map[string]func(seed string) (string, error){
+	"email": func(seed string) (string, error) {
+		return getFakeEmail(seed), nil
+	},
+	//benchmarking range 198.18.0.0/15 as by map mode of ip strategy
+	"ip": func(seed string) (string, error) {
+		hash := md5.Sum([]byte(seed))
+		return fmt.Sprintf("198.%v.%v.%v", 18+hash[0]&1, hash[1], hash[2]), nil
+	},
+}
+
+//script needs the row, so only the value is available if it's evaluated without table, e.g. in preview of JSON
+func scriptValue(rawValue interface{}, dbType string, params map[string]string) (interface{}, error) {
+	return evaluateScript(rawValue, dbType, params, nil)
+}
+
+//row contains original values by column names
+func ObfuscateValueWithScript(rawValue *interface{}, dbType string, params map[string]string,
+	row map[string]*interface{}) (interface{}, error) {
+	if rawValue == nil || *rawValue == nil {
+		return nil, nil
+	}
+	return evaluateScript(*rawValue, dbType, params, row)
+}
+
+//checks that the script is compiled
+func validateScriptParams(_ string, params map[string]string) error {
+	err := checkParams(params, ScriptParam)
+	if err != nil {
+		return err
+	}
+	_, err = getScript(params[ScriptParam])
+	return err
+}
+
+func evaluateScript(rawValue interface{}, dbType string, params map[string]string,
+	row map[string]*interface{}) (interface{}, error) {
+	function, err := getScript(params[ScriptParam])
+	if err != nil {
+		return nil, err
+	}
+	value, err := toStarlark(rawValue)
+	if err != nil {
+		return nil, err
+	}
+	rowDict := starlark.NewDict(len(row))
+	for column, pointer := range row {
+		var columnValue interface{}
+		if pointer != nil {
+			columnValue = *pointer
+		}
+		starlarkValue, err := toStarlark(columnValue)
+		if err != nil {
+			return nil, err
+		}
+		err = rowDict.SetKey(starlark.String(column), starlarkValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+	rowDict.Freeze()
+
+	thread := &starlark.Thread{Name: ScriptStrategy}
+	thread.SetMaxExecutionSteps(maxScriptSteps)
+	timeout := defaultScriptTimeout
+	if timeoutMs := config.GetConfig().Obfuscator.ScriptTimeoutMs; timeoutMs > 0 {
+		timeout = time.Duration(timeoutMs) * time.Millisecond
+	}
+	timer := time.AfterFunc(timeout, func() {
+		thread.Cancel("timeout")
+	})
+	defer timer.Stop()
+
+	result, err := starlark.Call(thread, function, starlark.Tuple{value, rowDict}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("script failed: %v", err)
+	}
+	return fromStarlark(result, dbType)
+}
+
+//script is compiled as the body of function of value and row, so it's parsed once
+func getScript(script string) (*starlark.Function, error) {
+	if script == "" {
+		return nil, fmt.Errorf("param %v is required", ScriptParam)
+	}
+	scriptsMutex.Lock()
+	defer scriptsMutex.Unlock()
+	if function, exists := scripts[script]; exists {
+		return function, nil
+	}
+
+	//the script is spliced into the function, so statements which would run on compilation are rejected
+	_, err := (&syntax.FileOptions{}).ParseExpr(ScriptStrategy, script, 0)
+	if err != nil {
+		return nil, fmt.Errorf("script must be single expression: %v", err)
+	}
+	source := fmt.Sprintf("def %v(value, row):\n    return (%v\n)\n", scriptFunctionName, script)
+	_, program, err := starlark.SourceProgramOptions(&syntax.FileOptions{}, ScriptStrategy, source,
+		scriptBuiltins.Has)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script: %v", err)
+	}
+	thread := &starlark.Thread{Name: ScriptStrategy}
+	thread.SetMaxExecutionSteps(maxScriptSteps)
+	globals, err := program.Init(thread, scriptBuiltins)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script: %v", err)
+	}
+	globals.Freeze()
+	function, ok := globals[scriptFunctionName].(*starlark.Function)
+	if !ok || len(globals) != 1 {
+		return nil, fmt.Errorf("script must be single expression")
+	}
+	scripts[script] = function
+	return function, nil
+}
+
+func toStarlark(value interface{}) (starlark.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return starlark.None, nil
+	case []byte:
+		if utf8.Valid(v) {
+			return starlark.String(v), nil
+		}
+		return starlark.Bytes(v), nil
+	case string:
+		return starlark.String(v), nil
+	case int64:
+		return starlark.MakeInt64(v), nil
+	case uint64:
+		return starlark.MakeUint64(v), nil
+	case float32:
+		return starlark.Float(v), nil
+	case float64:
+		return starlark.Float(v), nil
+	case bool:
+		return starlark.Bool(v), nil
+	case time.Time:
+		return starlark.String(v.Format(time.RFC3339Nano)), nil
+	default:
+		return nil, fmt.Errorf("type %T isn't supported by scripts", value)
+	}
+}
+
+//strings are trimmed to the column size, integers and floats are fitted to the column type
+func fromStarlark(value starlark.Value, dbType string) (interface{}, error) {
+	switch v := value.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.String:
+		result := string(v)
+		if strings.HasPrefix(dbType, CharType) || strings.HasPrefix(dbType, VarcharType) {
+			size, err := strconv.Atoi(getSubstringInSingleLastBrackets(dbType))
+			if err != nil {
+				return nil, err
+			}
+			result = trimStr(result, size)
+		}
+		return result, nil
+	case starlark.Bytes:
+		return []byte(v), nil
+	case starlark.Bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case starlark.Int:
+		//exact for integers which float64 can't represent
+		if integer, ok := v.Int64(); ok && IsIntType(dbType) {
+			lowerBound, upperBound := getIntBounds(dbType)
+			return min(max(integer, lowerBound), upperBound), nil
+		}
+		if integer, ok := v.Uint64(); ok && IsUintType(dbType) {
+			return min(integer, getUintUpperBound(dbType)), nil
+		}
+		if IsNumericType(dbType) {
+			number, _ := new(big.Float).SetInt(v.BigInt()).Float64()
+			return fitNumericType(number, dbType)
+		}
+		return fromStarlark(starlark.String(v.String()), dbType)
+	case starlark.Float:
+		if IsNumericType(dbType) {
+			return fitNumericType(float64(v), dbType)
+		}
+		return fromStarlark(starlark.String(strconv.FormatFloat(float64(v), 'f', -1, 64)), dbType)
+	default:
+		return nil, fmt.Errorf("script returned unsupported type %v", value.Type())
+	}
+}
+
+//hash(value): MD5 of the value as hex string
+func scriptHash(_ *starlark.Thread, builtin *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var value starlark.Value
+	err := starlark.UnpackPositionalArgs(builtin.Name(), args, kwargs, 1, &value)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.String(getMD5Hash(scriptString(value))), nil
+}
+
+//mask(value, keepFirst=0, keepLast=0, maskChar="*"), see MaskStrategy
+func scriptMask(_ *starlark.Thread, builtin *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var value starlark.Value
+	var keepFirst, keepLast int
+	maskChar := string(defaultMaskChar)
+	err := starlark.UnpackArgs(builtin.Name(), args, kwargs, "value", &value, "keepFirst?", &keepFirst,
+		"keepLast?", &keepLast, "maskChar?", &maskChar)
+	if err != nil {
+		return nil, err
+	}
+	result, err := maskValue(scriptString(value), TextType, map[string]string{
+		KeepFirstParam: strconv.Itoa(keepFirst),
+		KeepLastParam:  strconv.Itoa(keepLast),
+		MaskCharParam:  maskChar,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return starlark.String(result.(string)), nil
+}
+
+//fake(kind, seed): fake value of kind ("email" or "ip") which is the same for the same seed
+func scriptFake(_ *starlark.Thread, builtin *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var kind string
+	var seed starlark.Value
+	err := starlark.UnpackPositionalArgs(builtin.Name(), args, kwargs, 2, &kind, &seed)
+	if err != nil {
+		return nil, err
+	}
+	generate, exists := fakeGenerators[kind]
+	if !exists {
+		return nil, fmt.Errorf("%v: unknown kind %v", builtin.Name(), kind)
+	}
+	result, err := generate(scriptString(seed))
+	if err != nil {
+		return nil, err
+	}
+	return starlark.String(result), nil
+}
+
+//strings are taken without quotes, other values as they're printed by Starlark
+func scriptString(value starlark.Value) string {
+	switch v := value.(type) {
+	case starlark.String:
+		return string(v)
+	case starlark.Bytes:
+		return string(v)
+	default:
+		return value.String()
+	}
+}
@@ -0,0 +1,81 @@
+package encoding
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestObfuscateValueWithScript(t *testing.T) {
+	var country, id interface{} = []byte("US"), int64(7)
+	row := map[string]*interface{}{"country": &country, "id": &id, "comment": nil}
+	tests := []struct {
+		script   string
+		value    interface{}
+		dbType   string
+		expected interface{}
+	}{
+		{"mask(value, keepLast=4) if row['country'] == 'US' else hash(value)", "4111111111111234", TextType,
+			"************1234"},
+		{"value.upper() + '-' + str(row['id'])", []byte("abc"), "varchar(5)", "ABC-7"},
+		{"fake('email', value)", "john@mail.com", TextType, getFakeEmail("john@mail.com")},
+		{"value * 1000", int64(200), TinyintType, int64(UpperBoundTinyint)},
+		{"value // 2", uint64(9), UIntType, uint64(4)},
+		{"value / 4", int64(10), DoubleType, 2.5},
+		{"row['comment']", "text", TextType, nil},
+		{"len(value) > 3", "text", TinyintType, int64(1)},
+	}
+	for _, test := range tests {
+		value := test.value
+		result, err := ObfuscateValueWithScript(&value, test.dbType, map[string]string{ScriptParam: test.script}, row)
+		if err != nil {
+			t.Fatalf("%v: %v", test.script, err)
+		}
+		if result != test.expected {
+			t.Errorf("%v: got %v, expected %v", test.script, result, test.expected)
+		}
+	}
+}
+
+func TestScriptLimits(t *testing.T) {
+	var value interface{} = "text"
+	params := map[string]string{ScriptParam: "len([x for x in range(100000000)])"}
+	_, err := ObfuscateValueWithScript(&value, IntType, params, nil)
+	if err == nil || !strings.Contains(err.Error(), "script failed") {
+		t.Errorf("script exceeding the step limit must fail, got %v", err)
+	}
+	result, err := ObfuscateValueWithScript(nil, IntType, params, nil)
+	if err != nil || result != nil {
+		t.Errorf("NULL must stay NULL, got %v, %v", result, err)
+	}
+}
+
+//statements spliced after the expression would run on compilation without limits
+func TestValidateScriptParams(t *testing.T) {
+	tests := []struct {
+		script string
+		valid  bool
+	}{
+		{"hash(value)", true},
+		{"value, row", true},
+		{"(value\n  + 'x')", true},
+		{"hash(value) # comment", true},
+		{"", false},
+		{"hash(value", false},
+		{"1)\nx = [y for y in range(1000000000)]\ndef f(value, row): return (1", false},
+		{"1\nx = 2", false},
+		{"def f(): pass", false},
+		{"x = 1", false},
+		{"load('module', 'x')", false},
+		{"unknown(value)", false},
+	}
+	for _, test := range tests {
+		err := ValidateStrategy(ScriptStrategy, TextType, map[string]string{ScriptParam: test.script})
+		if (err == nil) != test.valid {
+			t.Errorf("%q: unexpected result %v", test.script, err)
+		}
+	}
+	err := ValidateStrategy(ScriptStrategy, TextType, map[string]string{ScriptParam: "value", "timeout": "1"})
+	if err == nil {
+		t.Errorf("unknown param must be rejected")
+	}
+}
@@ -0,0 +1,270 @@
+package encoding
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+//GeoStrategy params
+const (
+	//max distance of jitter in meters, 1000 by default
+	RadiusParam = "radius"
+	//size of grid cell in meters, coordinates are snapped to cell centers instead of jitter if it's set
+	GridParam = "grid"
+	//whether coordinates are longitude and latitude in degrees, by SRID of the value by default.
+	//Radius and grid are in units of coordinates (usually meters) for other SRIDs
+	GeographicParam = "geographic"
+)
+
+const (
+	defaultRadius   = 1000
+	metersPerDegree = 111320
+	maxLatitude     = 90
+	maxLongitude    = 180
+
+	//mysql stores 4 bytes SRID before WKB
+	sridSize = 4
+
+	wkbPoint              = 1
+	wkbLineString         = 2
+	wkbPolygon            = 3
+	wkbMultiPoint         = 4
+	wkbMultiLineString    = 5
+	wkbMultiPolygon       = 6
+	wkbGeometryCollection = 7
+)
+
+//well-known geographic SRIDs, e.g. WGS 84, NAD83, ETRS89, other SRIDs are treated as projected or cartesian
+var geographicSrids = map[uint32]bool{
+	4326: true, //WGS 84
+	4258: true, //ETRS89
+	4269: true, //NAD83
+	4267: true, //NAD27
+	4283: true, //GDA94
+	7844: true, //GDA2020
+	4617: true, //NAD83(CSRS)
+	4674: true, //SIRGAS 2000
+	4230: true, //ED50
+	4612: true, //JGD2000
+	6668: true, //JGD2011
+	4490: true, //CGCS2000
+	4167: true, //NZGD2000
+	4148: true, //Hartebeesthoek94
+	4019: true, //Unknown datum based upon the GRS 1980 ellipsoid
+}
+
+var spatialTypes = []string{GeometryType, PointType, LinestringType, PolygonType, MultipointType,
+	MultilinestringType, MultipolygonType, GeomcollectionType, GeometrycollectionType}
+
+//type can be followed by SRID comment, e.g. "point /*!80003 SRID 4326 */"
+func IsSpatialType(t string) bool {
+	fields := strings.Fields(t)
+	if len(fields) == 0 {
+		return false
+	}
+	for _, spatialType := range spatialTypes {
+		if fields[0] == spatialType {
+			return true
+		}
+	}
+	return false
+}
+
+//coordinates of geographic SRIDs are longitude and latitude in degrees, as mysql stores them,
+//coordinates of other SRIDs are moved in their own units.
+//The whole geometry is moved by the same offset to keep its shape valid
+func geoValue(rawValue interface{}, dbType string, params map[string]string) (interface{}, error) {
+	if !IsSpatialType(dbType) {
+		return nil, fmt.Errorf("geo strategy isn't applicable to type %v", dbType)
+	}
+	value, ok := rawValue.([]byte)
+	if !ok || len(value) < sridSize {
+		return nil, fmt.Errorf("unexpected value of type %v", dbType)
+	}
+	radius, err := getIntParam(params, RadiusParam, defaultRadius)
+	if err != nil {
+		return nil, err
+	}
+	grid, err := getIntParam(params, GridParam, 0)
+	if err != nil {
+		return nil, err
+	}
+	if radius < 0 || grid < 0 {
+		return nil, fmt.Errorf("radius and grid can't be negative")
+	}
+	geographic := geographicSrids[binary.LittleEndian.Uint32(value)]
+	if rawGeographic := params[GeographicParam]; rawGeographic != "" {
+		geographic, err = strconv.ParseBool(rawGeographic)
+		if err != nil {
+			return nil, fmt.Errorf("invalid param %v: %v", GeographicParam, err)
+		}
+	}
+
+	var transform func(x, y float64) (float64, float64)
+	switch {
+	case grid > 0 && geographic:
+		transform = func(x, y float64) (float64, float64) {
+			return snapToGrid(x, y, float64(grid))
+		}
+	case grid > 0:
+		transform = func(x, y float64) (float64, float64) {
+			return snapToCell(x, float64(grid)), snapToCell(y, float64(grid))
+		}
+	default:
+		//offset is uniform in the circle
+		distance := float64(radius) * math.Sqrt(rand.Float64())
+		angle := 2 * math.Pi * rand.Float64()
+		east, north := distance*math.Cos(angle), distance*math.Sin(angle)
+		transform = func(x, y float64) (float64, float64) {
+			if geographic {
+				return movePoint(x, y, east, north)
+			}
+			return x + east, y + north
+		}
+	}
+
+	result := make([]byte, len(value))
+	copy(result, value)
+	end, err := transformWkb(result, sridSize, transform)
+	if err != nil {
+		return nil, err
+	}
+	if end != len(result) {
+		return nil, fmt.Errorf("unexpected bytes after geometry")
+	}
+	return result, nil
+}
+
+func movePoint(longitude, latitude, eastMeters, northMeters float64) (float64, float64) {
+	latitude += northMeters / metersPerDegree
+	latitude = math.Max(-maxLatitude, math.Min(maxLatitude, latitude))
+	longitude += eastMeters / (metersPerDegree * math.Max(math.Cos(latitude*math.Pi/180), 0.01))
+	//wrapping to [-180, 180)
+	longitude = math.Mod(longitude+maxLongitude, 2*maxLongitude)
+	if longitude < 0 {
+		longitude += 2 * maxLongitude
+	}
+	return longitude - maxLongitude, latitude
+}
+
+//cells are square near the equator and get narrower towards poles
+func snapToGrid(longitude, latitude, cellMeters float64) (float64, float64) {
+	cellDegrees := cellMeters / metersPerDegree
+	latitude = math.Max(-maxLatitude, math.Min(maxLatitude, snapToCell(latitude, cellDegrees)))
+	longitude = math.Max(-maxLongitude, math.Min(maxLongitude, snapToCell(longitude, cellDegrees)))
+	return longitude, latitude
+}
+
+//returns center of the cell containing the coordinate
+func snapToCell(value, cellSize float64) float64 {
+	return (math.Floor(value/cellSize) + 0.5) * cellSize
+}
+
+func validateGeoParams(dbType string, params map[string]string) error {
+	if !IsSpatialType(dbType) {
+		return fmt.Errorf("geo strategy isn't applicable to type %v", dbType)
+	}
+	err := checkParams(params, RadiusParam, GridParam, GeographicParam)
+	if err != nil {
+		return err
+	}
+	radius, err := getIntParam(params, RadiusParam, defaultRadius)
+	if err != nil {
+		return err
+	}
+	grid, err := getIntParam(params, GridParam, 0)
+	if err != nil {
+		return err
+	}
+	if radius < 0 || grid < 0 {
+		return fmt.Errorf("radius and grid can't be negative")
+	}
+	if rawGeographic := params[GeographicParam]; rawGeographic != "" {
+		if _, err = strconv.ParseBool(rawGeographic); err != nil {
+			return fmt.Errorf("invalid param %v: %v", GeographicParam, err)
+		}
+	}
+	return nil
+}
+
+//changes coordinates in place, returns offset after the geometry
+func transformWkb(data []byte, offset int, transform func(x, y float64) (float64, float64)) (int, error) {
+	if offset+5 > len(data) {
+		return 0, fmt.Errorf("WKB is too short")
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if data[offset] == 0 {
+		order = binary.BigEndian
+	}
+	geometryType := order.Uint32(data[offset+1:])
+	offset += 5
+
+	readCount := func() (int, error) {
+		if offset+4 > len(data) {
+			return 0, fmt.Errorf("WKB is too short")
+		}
+		count := int(order.Uint32(data[offset:]))
+		offset += 4
+		return count, nil
+	}
+	transformPoints := func(count int) error {
+		if offset+count*16 > len(data) || count < 0 {
+			return fmt.Errorf("WKB is too short")
+		}
+		for i := 0; i < count; i++ {
+			x := math.Float64frombits(order.Uint64(data[offset:]))
+			y := math.Float64frombits(order.Uint64(data[offset+8:]))
+			x, y = transform(x, y)
+			order.PutUint64(data[offset:], math.Float64bits(x))
+			order.PutUint64(data[offset+8:], math.Float64bits(y))
+			offset += 16
+		}
+		return nil
+	}
+
+	switch geometryType {
+	case wkbPoint:
+		err := transformPoints(1)
+		return offset, err
+	case wkbLineString:
+		count, err := readCount()
+		if err != nil {
+			return 0, err
+		}
+		err = transformPoints(count)
+		return offset, err
+	case wkbPolygon:
+		rings, err := readCount()
+		if err != nil {
+			return 0, err
+		}
+		for i := 0; i < rings; i++ {
+			count, err := readCount()
+			if err != nil {
+				return 0, err
+			}
+			if err = transformPoints(count); err != nil {
+				return 0, err
+			}
+		}
+		return offset, nil
+	case wkbMultiPoint, wkbMultiLineString, wkbMultiPolygon, wkbGeometryCollection:
+		count, err := readCount()
+		if err != nil {
+			return 0, err
+		}
+		for i := 0; i < count; i++ {
+			offset, err = transformWkb(data, offset, transform)
+			if err != nil {
+				return 0, err
+			}
+		}
+		return offset, nil
+	default:
+		return 0, fmt.Errorf("unsupported WKB geometry type %v", geometryType)
+	}
+}
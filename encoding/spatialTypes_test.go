@@ -0,0 +1,144 @@
+package encoding
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+//mysql value of point: SRID and little-endian WKB
+func makePoint(srid uint32, x, y float64) []byte {
+	value := make([]byte, sridSize+21)
+	binary.LittleEndian.PutUint32(value, srid)
+	value[sridSize] = 1
+	binary.LittleEndian.PutUint32(value[sridSize+1:], wkbPoint)
+	binary.LittleEndian.PutUint64(value[sridSize+5:], math.Float64bits(x))
+	binary.LittleEndian.PutUint64(value[sridSize+13:], math.Float64bits(y))
+	return value
+}
+
+func readPoint(value []byte) (float64, float64) {
+	x := math.Float64frombits(binary.LittleEndian.Uint64(value[sridSize+5:]))
+	y := math.Float64frombits(binary.LittleEndian.Uint64(value[sridSize+13:]))
+	return x, y
+}
+
+func TestIsSpatialType(t *testing.T) {
+	for _, dbType := range []string{"point", "geometry", "point /*!80003 SRID 4326 */", "geomcollection"} {
+		if !IsSpatialType(dbType) {
+			t.Errorf("%v is spatial type", dbType)
+		}
+	}
+	for _, dbType := range []string{"pointer", "varchar(10)", ""} {
+		if IsSpatialType(dbType) {
+			t.Errorf("%v isn't spatial type", dbType)
+		}
+	}
+}
+
+func TestGeoValueGeographic(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		value, err := geoValue(makePoint(4326, 30, 89.999), PointType, map[string]string{RadiusParam: "1000"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		longitude, latitude := readPoint(value.([]byte))
+		if latitude > maxLatitude || longitude < -maxLongitude || longitude >= maxLongitude {
+			t.Fatalf("point %v %v is out of bounds", longitude, latitude)
+		}
+		if math.Abs(latitude-89.999) > 1000.0/metersPerDegree+1e-9 {
+			t.Fatalf("latitude %v is moved further than radius", latitude)
+		}
+	}
+
+	value, err := geoValue(makePoint(4326, 10.00001, 20.00001), PointType, map[string]string{GridParam: "1113200"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	longitude, latitude := readPoint(value.([]byte))
+	if longitude != 15 || latitude != 25 {
+		t.Errorf("point isn't snapped to center of 10 degrees cell: %v %v", longitude, latitude)
+	}
+}
+
+func TestGeoValueProjected(t *testing.T) {
+	//metre coordinates of projected SRID aren't clamped to degrees
+	for _, srid := range []uint32{0, 3857, 32633} {
+		value, err := geoValue(makePoint(srid, 500000, 5000000), PointType, map[string]string{RadiusParam: "100"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, y := readPoint(value.([]byte))
+		if math.Hypot(x-500000, y-5000000) > 100+1e-6 {
+			t.Errorf("SRID %v: point %v %v is moved further than radius", srid, x, y)
+		}
+		if binary.LittleEndian.Uint32(value.([]byte)) != srid {
+			t.Errorf("SRID %v isn't kept", srid)
+		}
+	}
+
+	value, err := geoValue(makePoint(3857, 1234, -1234), PointType, map[string]string{GridParam: "1000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y := readPoint(value.([]byte))
+	if x != 1500 || y != -1500 {
+		t.Errorf("point isn't snapped to center of cell: %v %v", x, y)
+	}
+
+	value, err = geoValue(makePoint(0, 30, 200), PointType, map[string]string{RadiusParam: "0",
+		GeographicParam: "false"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x, y = readPoint(value.([]byte)); x != 30 || y != 200 {
+		t.Errorf("cartesian point is corrupted: %v %v", x, y)
+	}
+}
+
+func TestGeoValueLineString(t *testing.T) {
+	value := make([]byte, sridSize+9+32)
+	value[sridSize] = 1
+	binary.LittleEndian.PutUint32(value[sridSize+1:], wkbLineString)
+	binary.LittleEndian.PutUint32(value[sridSize+5:], 2)
+	for i, coordinate := range []float64{0, 0, 100, 0} {
+		binary.LittleEndian.PutUint64(value[sridSize+9+i*8:], math.Float64bits(coordinate))
+	}
+	result, err := geoValue(value, LinestringType, map[string]string{RadiusParam: "10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	coordinates := make([]float64, 4)
+	for i := range coordinates {
+		coordinates[i] = math.Float64frombits(binary.LittleEndian.Uint64(result.([]byte)[sridSize+9+i*8:]))
+	}
+	//the whole geometry is moved by the same offset
+	if math.Abs(coordinates[2]-coordinates[0]-100) > 1e-9 || math.Abs(coordinates[3]-coordinates[1]) > 1e-9 {
+		t.Errorf("shape isn't kept: %v", coordinates)
+	}
+
+	if _, err = geoValue(value[:len(value)-1], LinestringType, nil); err == nil {
+		t.Error("truncated WKB must fail")
+	}
+}
+
+func TestValidateGeoParams(t *testing.T) {
+	tests := []struct {
+		dbType string
+		params map[string]string
+		valid  bool
+	}{
+		{PointType, map[string]string{RadiusParam: "50"}, true},
+		{GeometryType, map[string]string{GridParam: "1000", GeographicParam: "true"}, true},
+		{"varchar(10)", nil, false},
+		{PointType, map[string]string{RadiusParam: "-1"}, false},
+		{PointType, map[string]string{"radious": "50"}, false},
+		{PointType, map[string]string{GeographicParam: "maybe"}, false},
+	}
+	for _, test := range tests {
+		err := ValidateStrategy(GeoStrategy, test.dbType, test.params)
+		if (err == nil) != test.valid {
+			t.Errorf("%v %v: unexpected result %v", test.dbType, test.params, err)
+		}
+	}
+}
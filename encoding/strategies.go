@@ -0,0 +1,218 @@
+package encoding
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//obfuscating strategies
+const (
+	DefaultStrategy = "" //obfuscating by column type, see ObfuscateValue
+	KeepStrategy    = "keep"
+	NullStrategy    = "null"
+	HashStrategy    = "hash"
+	NoiseStrategy   = "noise"
+	MemberStrategy  = "member" //ENUM and SET, see discreteTypes.go
+	BitStrategy     = "bit"    //BIT and BOOLEAN
+	YearStrategy    = "year"
+	EmailStrategy   = "email"
+	JsonStrategy    = "json" //params are JSONPath rules, see jsonDocuments.go
+	//BINARY, VARBINARY and BLOB, see binaryTypes.go
+	RandomBytesStrategy = "bytes"
+	PlaceholderStrategy = "placeholder"
+	TruncateStrategy    = "truncate"
+	GeoStrategy         = "geo" //spatial types, see spatialTypes.go
+	IpStrategy          = "ip"  //text and binary columns, see networkAddresses.go
+	FpeStrategy         = "fpe" //reversible, see formatPreserving.go
+	MaskStrategy        = "mask"
+	LoremStrategy       = "lorem" //text of the same length and structure, see loremText.go
+	//numeric, see numericStrategies.go
+	QuantileStrategy = "quantile"
+	RoundStrategy    = "round"
+	LaplaceStrategy  = "laplace"
+	//values are permuted across rows of the table by obfuscating package, see GroupByParam
+	ShuffleStrategy = "shuffle"
+	//consistent pseudonyms stored in local vault, see pseudonyms.go
+	VaultStrategy = "vault"
+	//values are rendered from other columns of the row by obfuscating package, see templates.go
+	TemplateStrategy = "template"
+	//columns of the same reference set get values of one its row by obfuscating package, see tuples.go
+	TupleStrategy = "tuple"
+	//Starlark expression of the value and the row, see scripts.go
+	ScriptStrategy = "script"
+)
+
+//ShuffleStrategy params
+const (
+	//values are permuted only between rows with the same value of this column
+	GroupByParam = "groupBy"
+	//columns of the same set are permuted together, so their values stay consistent, e.g. city and postcode
+	ShuffleSetParam = "set"
+)
+
+//strategy fails with this error when keeping the original value would leak it, e.g. fpe value which can't be encrypted,
+//so the job must be failed instead of inserting the original value
+var ErrNoFallback = errors.New("original value can't be kept")
+
+type StrategyFunc func(rawValue interface{}, dbType string, params map[string]string) (interface{}, error)
+
+var strategies = map[string]StrategyFunc{
+	DefaultStrategy: obfuscateByType,
+	KeepStrategy:    keepValue,
+	NullStrategy:    nullValue,
+	HashStrategy:    hashValue,
+	NoiseStrategy:   noiseValue,
+	MemberStrategy:  memberValue,
+	BitStrategy:     bitValue,
+	YearStrategy:    yearValue,
+	EmailStrategy:   emailValue,
+	JsonStrategy:    jsonValue,
+
+	RandomBytesStrategy: randomBytesValue,
+	PlaceholderStrategy: placeholderValue,
+	TruncateStrategy:    truncateValue,
+	GeoStrategy:         geoValue,
+	IpStrategy:          ipValue,
+	FpeStrategy:         fpeValue,
+	MaskStrategy:        maskValue,
+	LoremStrategy:       loremValue,
+	QuantileStrategy:    quantileValue,
+	RoundStrategy:       roundValue,
+	LaplaceStrategy:     laplaceValue,
+	ShuffleStrategy:     shuffleValue,
+	TemplateStrategy:    templateValue,
+	TupleStrategy:       tupleValue,
+	ScriptStrategy:      scriptValue,
+}
+
+func init() {
+	//vault strategy refers to the registries
+	strategies[VaultStrategy] = vaultValue
+	validators[VaultStrategy] = validateVaultParams
+}
+
+func ObfuscateValueWithStrategy(rawValue *interface{}, dbType, strategy string, params map[string]string) (interface{}, error) {
+	if rawValue == nil || *rawValue == nil {
+		return nil, nil
+	}
+	obfuscate, exists := strategies[strategy]
+	if !exists {
+		return nil, fmt.Errorf("unknown strategy: %v", strategy)
+	}
+	return obfuscate(*rawValue, dbType, params)
+}
+
+func IsKnownStrategy(strategy string) bool {
+	_, exists := strategies[strategy]
+	return exists
+}
+
+func IsIntType(t string) bool {
+	return t == TinyintType || t == SmallintType || t == MediumintType || t == IntType || t == BigintType
+}
+
+func IsUintType(t string) bool {
+	return t == UTinyintType || t == USmallintType || t == UMediumintType || t == UIntType || t == UBigintType
+}
+
+func IsFloatType(t string) bool {
+	return t == FloatType || t == DoubleType || strings.HasPrefix(t, DecimalType)
+}
+
+func IsNumericType(t string) bool {
+	return IsIntType(t) || IsUintType(t) || IsFloatType(t)
+}
+
+func IsStringType(t string) bool {
+	return strings.HasPrefix(t, CharType) || strings.HasPrefix(t, VarcharType) ||
+		t == TinytextType || t == TextType || t == MediumtextType || t == LongtextType
+}
+
+func IsEnumType(t string) bool {
+	return strings.HasPrefix(t, EnumType+"(")
+}
+
+func IsSetType(t string) bool {
+	return strings.HasPrefix(t, SetType+"(")
+}
+
+func IsBitType(t string) bool {
+	return t == BitType || strings.HasPrefix(t, BitType+"(")
+}
+
+func IsBooleanType(t string) bool {
+	return t == BooleanType
+}
+
+//"year(4)" is shown by old mysql versions
+func IsYearType(t string) bool {
+	return t == YearType || strings.HasPrefix(t, YearType+"(")
+}
+
+func IsDiscreteType(t string) bool {
+	return IsEnumType(t) || IsSetType(t) || IsBitType(t) || IsBooleanType(t) || IsYearType(t)
+}
+
+func IsJsonType(t string) bool {
+	return t == JsonType
+}
+
+//types which can be obfuscated by default strategy
+func IsSupportedType(t string) bool {
+	return IsNumericType(t) || IsStringType(t) || IsDiscreteType(t) || IsJsonType(t) || IsBinaryType(t) ||
+		IsSpatialType(t)
+}
+
+func obfuscateByType(rawValue interface{}, dbType string, _ map[string]string) (interface{}, error) {
+	return ObfuscateValue(&rawValue, dbType)
+}
+
+func keepValue(rawValue interface{}, _ string, _ map[string]string) (interface{}, error) {
+	return rawValue, nil
+}
+
+func nullValue(_ interface{}, _ string, _ map[string]string) (interface{}, error) {
+	return nil, nil
+}
+
+func hashValue(rawValue interface{}, dbType string, _ map[string]string) (interface{}, error) {
+	if !IsStringType(dbType) {
+		return nil, fmt.Errorf("hash strategy isn't applicable to type %v", dbType)
+	}
+	return obfuscateString(rawValue, dbType)
+}
+
+func noiseValue(rawValue interface{}, dbType string, _ map[string]string) (interface{}, error) {
+	if !IsNumericType(dbType) {
+		return nil, fmt.Errorf("noise strategy isn't applicable to type %v", dbType)
+	}
+	return ObfuscateValue(&rawValue, dbType)
+}
+
+//fake email is trimmed if the column is too short for it
+func emailValue(rawValue interface{}, dbType string, _ map[string]string) (interface{}, error) {
+	if !IsStringType(dbType) {
+		return nil, fmt.Errorf("email strategy isn't applicable to type %v", dbType)
+	}
+	value := getFakeEmail(asString(rawValue))
+	if strings.HasPrefix(dbType, CharType) || strings.HasPrefix(dbType, VarcharType) {
+		size, err := strconv.Atoi(getSubstringInSingleLastBrackets(dbType))
+		if err != nil {
+			return nil, err
+		}
+		value = trimStr(value, size)
+	}
+	return value, nil
+}
+
+//shuffling needs values of the whole table, so single value can't be shuffled
+func shuffleValue(_ interface{}, _ string, _ map[string]string) (interface{}, error) {
+	return nil, fmt.Errorf("shuffle strategy is applicable only to table columns")
+}
+
+//columns of groups and sets are checked against the table by obfuscating package
+func validateShuffleParams(_ string, params map[string]string) error {
+	return checkParams(params, GroupByParam, ShuffleSetParam)
+}
@@ -0,0 +1,168 @@
+package encoding
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//TemplateStrategy params
+const (
+	//e.g. "{first_name} {last_name}" or "{first_name|lower}.{last_name|slug}@example.com",
+	//columns are substituted by their obfuscated values of the same row
+	TemplateParam = "template"
+)
+
+//template filters
+const (
+	LowerFilter   = "lower"
+	UpperFilter   = "upper"
+	InitialFilter = "initial" //first character
+	SlugFilter    = "slug"    //lower case letters and digits only
+)
+
+var templateFilters = map[string]func(string) string{
+	LowerFilter: strings.ToLower,
+	UpperFilter: strings.ToUpper,
+	InitialFilter: func(value string) string {
+		for _, r := range value {
+			return string(r)
+		}
+		return ""
+	},
+	SlugFilter: func(value string) string {
+		var sb strings.Builder
+		for _, r := range strings.ToLower(value) {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				sb.WriteRune(r)
+			}
+		}
+		return sb.String()
+	},
+}
+
+type templatePart struct {
+	literal string
+	column  string
+	filters []string
+}
+
+//template columns depend on other columns of the row, so single value can't be rendered
+func templateValue(_ interface{}, _ string, _ map[string]string) (interface{}, error) {
+	return nil, fmt.Errorf("template strategy is applicable only to table rows")
+}
+
+func validateTemplateParams(dbType string, params map[string]string) error {
+	if !IsStringType(dbType) {
+		return fmt.Errorf("template strategy isn't applicable to type %v", dbType)
+	}
+	err := checkParams(params, TemplateParam)
+	if err != nil {
+		return err
+	}
+	_, err = parseTemplate(params[TemplateParam])
+	return err
+}
+
+//returns columns referenced by the template
+func GetTemplateColumns(params map[string]string) ([]string, error) {
+	parts, err := parseTemplate(params[TemplateParam])
+	if err != nil {
+		return nil, err
+	}
+	var columns []string
+	for _, part := range parts {
+		if part.column != "" {
+			columns = append(columns, part.column)
+		}
+	}
+	return columns, nil
+}
+
+//row contains obfuscated values by column names, NULL values are rendered as empty strings
+func RenderTemplate(dbType string, params map[string]string, row map[string]interface{}) (interface{}, error) {
+	if !IsStringType(dbType) {
+		return nil, fmt.Errorf("template strategy isn't applicable to type %v", dbType)
+	}
+	parts, err := parseTemplate(params[TemplateParam])
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	for _, part := range parts {
+		if part.column == "" {
+			sb.WriteString(part.literal)
+			continue
+		}
+		value, exists := row[part.column]
+		if !exists {
+			return nil, fmt.Errorf("row hasn't column %v", part.column)
+		}
+		text := ""
+		if pointer, ok := value.(*interface{}); ok && pointer != nil {
+			value = *pointer
+		}
+		if value != nil {
+			text = asString(value)
+		}
+		for _, filter := range part.filters {
+			text = templateFilters[filter](text)
+		}
+		sb.WriteString(text)
+	}
+
+	return fitStringType(sb.String(), dbType)
+}
+
+//trims the value to the size of CHAR and VARCHAR columns
+func fitStringType(value, dbType string) (interface{}, error) {
+	if strings.HasPrefix(dbType, CharType) || strings.HasPrefix(dbType, VarcharType) {
+		size, err := strconv.Atoi(getSubstringInSingleLastBrackets(dbType))
+		if err != nil {
+			return nil, err
+		}
+		value = trimStr(value, size)
+	}
+	return value, nil
+}
+
+//literal braces aren't supported
+func parseTemplate(template string) ([]templatePart, error) {
+	if template == "" {
+		return nil, fmt.Errorf("param %v is required", TemplateParam)
+	}
+	var parts []templatePart
+	for template != "" {
+		start := strings.IndexAny(template, "{}")
+		if start < 0 {
+			parts = append(parts, templatePart{literal: template})
+			break
+		}
+		if template[start] == '}' {
+			return nil, fmt.Errorf("unexpected } in template")
+		}
+		if start > 0 {
+			parts = append(parts, templatePart{literal: template[:start]})
+		}
+		end := strings.IndexAny(template[start+1:], "{}")
+		if end < 0 || template[start+1+end] != '}' {
+			return nil, fmt.Errorf("unclosed { in template")
+		}
+		names := strings.Split(template[start+1:start+1+end], "|")
+		part := templatePart{column: strings.TrimSpace(names[0])}
+		if part.column == "" {
+			return nil, fmt.Errorf("empty column name in template")
+		}
+		for _, filter := range names[1:] {
+			filter = strings.TrimSpace(filter)
+			if _, exists := templateFilters[filter]; !exists {
+				return nil, fmt.Errorf("unknown template filter %v", filter)
+			}
+			part.filters = append(part.filters, filter)
+		}
+		parts = append(parts, part)
+		template = template[start+end+2:]
+	}
+	return parts, nil
+}
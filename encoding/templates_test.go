@@ -0,0 +1,69 @@
+package encoding
+
+import "testing"
+
+func TestRenderTemplate(t *testing.T) {
+	var first, last interface{} = "Anna-Maria", "O'Neil"
+	row := map[string]interface{}{"first_name": &first, "last_name": &last, "middle_name": nil}
+	tests := []struct {
+		template string
+		dbType   string
+		expected string
+	}{
+		{"{first_name} {last_name}", TextType, "Anna-Maria O'Neil"},
+		{"{first_name|slug}.{ last_name | slug }@example.com", TextType, "annamaria.oneil@example.com"},
+		{"{first_name|initial|lower}{middle_name}{last_name|upper}", TextType, "aO'NEIL"},
+		{"{first_name} {last_name}", "varchar(8)", "Anna-Mar"},
+		{"no columns", "char(20)", "no columns"},
+	}
+	for _, test := range tests {
+		value, err := RenderTemplate(test.dbType, map[string]string{TemplateParam: test.template}, row)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != test.expected {
+			t.Errorf("%v: got %v, expected %v", test.template, value, test.expected)
+		}
+	}
+	if _, err := RenderTemplate(TextType, map[string]string{TemplateParam: "{email}"}, row); err == nil {
+		t.Errorf("unknown column must fail")
+	}
+}
+
+func TestGetTemplateColumns(t *testing.T) {
+	columns, err := GetTemplateColumns(map[string]string{TemplateParam: "{a|lower}-{b}@{a}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(columns) != 3 || columns[0] != "a" || columns[1] != "b" || columns[2] != "a" {
+		t.Errorf("unexpected columns %v", columns)
+	}
+}
+
+func TestValidateTemplateParams(t *testing.T) {
+	tests := []struct {
+		dbType   string
+		template string
+		valid    bool
+	}{
+		{"varchar(40)", "{first_name|lower}.{last_name}@example.com", true},
+		{TextType, "constant", true},
+		{IntType, "{id}", false},
+		{"varbinary(40)", "{name}", false},
+		{TextType, "", false},
+		{TextType, "{name", false},
+		{TextType, "name}", false},
+		{TextType, "{}", false},
+		{TextType, "{name|title}", false},
+	}
+	for _, test := range tests {
+		err := ValidateStrategy(TemplateStrategy, test.dbType, map[string]string{TemplateParam: test.template})
+		if (err == nil) != test.valid {
+			t.Errorf("%v %v: unexpected result %v", test.dbType, test.template, err)
+		}
+	}
+	err := ValidateStrategy(TemplateStrategy, TextType, map[string]string{TemplateParam: "{a}", "filter": "lower"})
+	if err == nil {
+		t.Errorf("unknown param must be rejected")
+	}
+}
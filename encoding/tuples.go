@@ -0,0 +1,167 @@
+package encoding
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"obfuscator/config"
+	"os"
+	"strings"
+	"sync"
+)
+
+//TupleStrategy params
+const (
+	//reference set, built-in or set in config, columns of the same set get values of one its row,
+	//e.g. city, postcode and country matching each other
+	TupleSetParam = "set"
+	//field of the reference set taken by the column, e.g. "city"
+	TupleFieldParam = "field"
+)
+
+//built-in reference sets
+const (
+	AddressReferenceSet = "address"
+)
+
+type referenceSet struct {
+	fields []string
+	rows   [][]string
+}
+
+var referenceSets = map[string]referenceSet{
+	AddressReferenceSet: {
+		fields: []string{"city", "postcode", "country", "countryCode"},
+		rows: [][]string{
+			{"Berlin", "10115", "Germany", "DE"},
+			{"Munich", "80331", "Germany", "DE"},
+			{"Hamburg", "20095", "Germany", "DE"},
+			{"Paris", "75001", "France", "FR"},
+			{"Lyon", "69001", "France", "FR"},
+			{"Madrid", "28001", "Spain", "ES"},
+			{"Barcelona", "08001", "Spain", "ES"},
+			{"Rome", "00118", "Italy", "IT"},
+			{"Milan", "20121", "Italy", "IT"},
+			{"Amsterdam", "1012 AB", "Netherlands", "NL"},
+			{"Vienna", "1010", "Austria", "AT"},
+			{"Warsaw", "00-001", "Poland", "PL"},
+			{"Stockholm", "111 20", "Sweden", "SE"},
+			{"London", "SW1A 1AA", "United Kingdom", "GB"},
+			{"Manchester", "M1 1AE", "United Kingdom", "GB"},
+			{"New York", "10001", "United States", "US"},
+			{"Chicago", "60601", "United States", "US"},
+			{"Toronto", "M5H 2N2", "Canada", "CA"},
+			{"Sydney", "2000", "Australia", "AU"},
+			{"Tokyo", "100-0001", "Japan", "JP"},
+		},
+	},
+}
+
+var (
+	referenceSetFiles      = make(map[string]referenceSet)
+	referenceSetFilesMutex sync.Mutex
+)
+
+//tuple columns depend on other columns of the row, so single value can't be obfuscated
+func tupleValue(_ interface{}, _ string, _ map[string]string) (interface{}, error) {
+	return nil, fmt.Errorf("tuple strategy is applicable only to table rows")
+}
+
+func validateTupleParams(dbType string, params map[string]string) error {
+	if !IsStringType(dbType) {
+		return fmt.Errorf("tuple strategy isn't applicable to type %v", dbType)
+	}
+	err := checkParams(params, TupleSetParam, TupleFieldParam)
+	if err != nil {
+		return err
+	}
+	_, _, err = getTupleField(params)
+	return err
+}
+
+//returns field of the row of the reference set chosen by original values of all columns of the set in the row,
+//so the same original tuple gets the same values
+func TupleValue(dbType string, params map[string]string, originals []interface{}) (interface{}, error) {
+	if !IsStringType(dbType) {
+		return nil, fmt.Errorf("tuple strategy isn't applicable to type %v", dbType)
+	}
+	set, field, err := getTupleField(params)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.New()
+	hash.Write([]byte(params[TupleSetParam]))
+	for _, original := range originals {
+		if pointer, ok := original.(*interface{}); ok {
+			original = nil
+			if pointer != nil {
+				original = *pointer
+			}
+		}
+		//NULL differs from empty string
+		if original == nil {
+			hash.Write([]byte{0})
+		} else {
+			hash.Write([]byte{1})
+			hash.Write([]byte(asString(original)))
+		}
+	}
+	sum := hash.Sum(nil)
+	row := set.rows[binary.BigEndian.Uint64(sum[:8])%uint64(len(set.rows))]
+	return fitStringType(row[field], dbType)
+}
+
+//returns reference set and index of the field
+func getTupleField(params map[string]string) (referenceSet, int, error) {
+	name := params[TupleSetParam]
+	if name == "" {
+		return referenceSet{}, 0, fmt.Errorf("param %v is required", TupleSetParam)
+	}
+	set, err := getReferenceSet(name)
+	if err != nil {
+		return referenceSet{}, 0, err
+	}
+	for i, field := range set.fields {
+		if field == params[TupleFieldParam] {
+			return set, i, nil
+		}
+	}
+	return referenceSet{}, 0, fmt.Errorf("reference set %v hasn't field %q, its fields are %v", name,
+		params[TupleFieldParam], strings.Join(set.fields, ", "))
+}
+
+//files set in config override built-in sets
+func getReferenceSet(name string) (referenceSet, error) {
+	if path, exists := config.GetConfig().Obfuscator.ReferenceSets[name]; exists {
+		return readReferenceSetFile(path)
+	}
+	if set, exists := referenceSets[name]; exists {
+		return set, nil
+	}
+	return referenceSet{}, fmt.Errorf("unknown reference set %v", name)
+}
+
+//CSV file with header of field names
+func readReferenceSetFile(path string) (referenceSet, error) {
+	referenceSetFilesMutex.Lock()
+	defer referenceSetFilesMutex.Unlock()
+	if set, exists := referenceSetFiles[path]; exists {
+		return set, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return referenceSet{}, err
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return referenceSet{}, fmt.Errorf("reference set %v: %v", path, err)
+	}
+	if len(records) < 2 {
+		return referenceSet{}, fmt.Errorf("reference set %v must have header and at least one row", path)
+	}
+	set := referenceSet{fields: records[0], rows: records[1:]}
+	referenceSetFiles[path] = set
+	return set, nil
+}
@@ -0,0 +1,92 @@
+package encoding
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTupleValue(t *testing.T) {
+	var city, postcode interface{} = []byte("Springfield"), "12345"
+	originals := []interface{}{&city, &postcode}
+	getTuple := func(originals []interface{}) []interface{} {
+		var tuple []interface{}
+		for _, field := range []string{"city", "postcode", "country"} {
+			value, err := TupleValue("varchar(20)", map[string]string{TupleSetParam: AddressReferenceSet,
+				TupleFieldParam: field}, originals)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tuple = append(tuple, value)
+		}
+		return tuple
+	}
+
+	tuple := getTuple(originals)
+	found := false
+	for _, row := range referenceSets[AddressReferenceSet].rows {
+		found = found || row[0] == tuple[0] && row[1] == tuple[1] && row[2] == tuple[2]
+	}
+	if !found {
+		t.Errorf("fields %v must be taken from one row of the reference set", tuple)
+	}
+	if again := getTuple([]interface{}{"Springfield", "12345"}); again[0] != tuple[0] || again[1] != tuple[1] {
+		t.Errorf("the same originals must get the same row, got %v and %v", tuple, again)
+	}
+
+	value, err := TupleValue("char(3)", map[string]string{TupleSetParam: AddressReferenceSet,
+		TupleFieldParam: "city"}, originals)
+	if err != nil || len([]rune(value.(string))) > 3 {
+		t.Errorf("value must fit the column, got %v, %v", value, err)
+	}
+}
+
+func TestValidateTupleParams(t *testing.T) {
+	tests := []struct {
+		dbType string
+		params map[string]string
+		valid  bool
+	}{
+		{"varchar(20)", map[string]string{TupleSetParam: AddressReferenceSet, TupleFieldParam: "postcode"}, true},
+		{TextType, map[string]string{TupleSetParam: AddressReferenceSet, TupleFieldParam: "countryCode"}, true},
+		{IntType, map[string]string{TupleSetParam: AddressReferenceSet, TupleFieldParam: "postcode"}, false},
+		{TextType, map[string]string{TupleFieldParam: "city"}, false},
+		{TextType, map[string]string{TupleSetParam: "planets", TupleFieldParam: "city"}, false},
+		{TextType, map[string]string{TupleSetParam: AddressReferenceSet}, false},
+		{TextType, map[string]string{TupleSetParam: AddressReferenceSet, TupleFieldParam: "street"}, false},
+		{TextType, map[string]string{TupleSetParam: AddressReferenceSet, TupleFieldParam: "city", "seed": "1"},
+			false},
+	}
+	for _, test := range tests {
+		err := ValidateStrategy(TupleStrategy, test.dbType, test.params)
+		if (err == nil) != test.valid {
+			t.Errorf("%v %v: unexpected result %v", test.dbType, test.params, err)
+		}
+	}
+}
+
+func TestReadReferenceSetFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"cities.csv": "city,zip\nSpringfield,12345\n\"Salt Lake City\",84101\n",
+		"empty.csv":  "city,zip\n",
+		"broken.csv": "city,zip\nSpringfield\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	set, err := readReferenceSetFile(filepath.Join(dir, "cities.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.fields) != 2 || len(set.rows) != 2 || set.rows[1][0] != "Salt Lake City" {
+		t.Errorf("unexpected reference set %v", set)
+	}
+	for _, name := range []string{"empty.csv", "broken.csv", "missing.csv"} {
+		if _, err = readReferenceSetFile(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%v must be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package encoding
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//checks strategy params against column type before obfuscating,
+//so a wrong param fails validation of the model instead of every value
+type ParamsValidator func(dbType string, params map[string]string) error
+
+var validators = map[string]ParamsValidator{
+	DefaultStrategy: noParams,
+	KeepStrategy:    noParams,
+	NullStrategy:    noParams,
+	HashStrategy:    typeValidator(IsStringType, HashStrategy),
+	NoiseStrategy:   typeValidator(IsNumericType, NoiseStrategy),
+	EmailStrategy:   typeValidator(IsStringType, EmailStrategy),
+	MemberStrategy:  validateMemberParams,
+	BitStrategy:     validateBitParams,
+	YearStrategy:    validateYearParams,
+	JsonStrategy:    validateJsonParams,
+
+	RandomBytesStrategy: typeValidator(IsBinaryType, RandomBytesStrategy),
+	PlaceholderStrategy: validatePlaceholderParams,
+	TruncateStrategy:    validateTruncateParams,
+	GeoStrategy:         validateGeoParams,
+	IpStrategy:          validateIpParams,
+	FpeStrategy:         validateFpeParams,
+	MaskStrategy:        validateMaskParams,
+	QuantileStrategy:    validateQuantileParams,
+	RoundStrategy:       validateRoundParams,
+	LaplaceStrategy:     validateLaplaceParams,
+	TemplateStrategy:    validateTemplateParams,
+	TupleStrategy:       validateTupleParams,
+	ScriptStrategy:      validateScriptParams,
+	ShuffleStrategy:     validateShuffleParams,
+	LoremStrategy:       typeValidator(IsStringType, LoremStrategy),
+}
+
+func ValidateStrategy(strategy, dbType string, params map[string]string) error {
+	if !IsKnownStrategy(strategy) {
+		return fmt.Errorf("unknown strategy %v", strategy)
+	}
+	validate, exists := validators[strategy]
+	if !exists {
+		return nil
+	}
+	return validate(dbType, params)
+}
+
+//strategies without params applicable to types by the check
+func typeValidator(isApplicable func(string) bool, strategy string) ParamsValidator {
+	return func(dbType string, params map[string]string) error {
+		if !isApplicable(dbType) {
+			return fmt.Errorf("%v strategy isn't applicable to type %v", strategy, dbType)
+		}
+		return checkParams(params)
+	}
+}
+
+func noParams(_ string, params map[string]string) error {
+	return checkParams(params)
+}
+
+//rejects params which aren't known, e.g. with typos
+func checkParams(params map[string]string, known ...string) error {
+	var unknown []string
+	for name := range params {
+		isKnown := false
+		for _, knownName := range known {
+			if name == knownName {
+				isKnown = true
+				break
+			}
+		}
+		if !isKnown {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown params: %v", strings.Join(unknown, ", "))
+	}
+	return nil
+}
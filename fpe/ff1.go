@@ -0,0 +1,120 @@
+package fpe
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"math"
+	"math/big"
+)
+
+const ff1Rounds = 10
+
+//FF1 of NIST SP 800-38G
+type FF1 struct {
+	block cipher.Block
+	radix int
+}
+
+func NewFF1(key []byte, radix int) (*FF1, error) {
+	if err := validateRadix(radix); err != nil {
+		return nil, err
+	}
+	block, err := newBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	return &FF1{block: block, radix: radix}, nil
+}
+
+func (c *FF1) Encrypt(x []int, tweak []byte) ([]int, error) {
+	return c.cipher(x, tweak, true)
+}
+
+func (c *FF1) Decrypt(x []int, tweak []byte) ([]int, error) {
+	return c.cipher(x, tweak, false)
+}
+
+func (c *FF1) cipher(x []int, tweak []byte, encrypt bool) ([]int, error) {
+	if err := validateNumerals(x, c.radix, math.MaxUint32); err != nil {
+		return nil, err
+	}
+	n := len(x)
+	u := n / 2
+	v := n - u
+	a := append([]int{}, x[:u]...)
+	b := append([]int{}, x[u:]...)
+
+	byteCount := (int(math.Ceil(float64(v)*math.Log2(float64(c.radix)))) + 7) / 8
+	d := 4*((byteCount+3)/4) + 4
+
+	p := make([]byte, 16)
+	p[0], p[1], p[2] = 1, 2, 1
+	p[3], p[4], p[5] = byte(c.radix>>16), byte(c.radix>>8), byte(c.radix)
+	p[6] = 10
+	p[7] = byte(u)
+	binary.BigEndian.PutUint32(p[8:], uint32(n))
+	binary.BigEndian.PutUint32(p[12:], uint32(len(tweak)))
+
+	padding := (16 - (len(tweak)+byteCount+1)%16) % 16
+	q := make([]byte, len(tweak)+padding+1+byteCount)
+	copy(q, tweak)
+
+	modU, modV := pow(c.radix, u), pow(c.radix, v)
+	for round := 0; round < ff1Rounds; round++ {
+		i := round
+		if !encrypt {
+			i = ff1Rounds - 1 - round
+		}
+		m, modulus := u, modU
+		if i%2 == 1 {
+			m, modulus = v, modV
+		}
+
+		//round function is applied to B when encrypting and to A when decrypting
+		source := b
+		if !encrypt {
+			source = a
+		}
+		q[len(tweak)+padding] = byte(i)
+		putBytes(q[len(q)-byteCount:], num(source, c.radix))
+		y := new(big.Int).SetBytes(c.expand(c.prf(append(append([]byte{}, p...), q...)), d))
+
+		if encrypt {
+			value := num(a, c.radix)
+			value.Add(value, y).Mod(value, modulus)
+			a, b = b, str(value, c.radix, m)
+		} else {
+			value := num(b, c.radix)
+			value.Sub(value, y).Mod(value, modulus)
+			b, a = a, str(value, c.radix, m)
+		}
+	}
+	return append(a, b...), nil
+}
+
+//CBC-MAC with zero IV
+func (c *FF1) prf(data []byte) []byte {
+	result := make([]byte, 16)
+	for i := 0; i < len(data); i += 16 {
+		for j := 0; j < 16; j++ {
+			result[j] ^= data[i+j]
+		}
+		c.block.Encrypt(result, result)
+	}
+	return result
+}
+
+//S = R || CIPH(R xor [1]) || CIPH(R xor [2]) ..., first d bytes
+func (c *FF1) expand(r []byte, d int) []byte {
+	s := append([]byte{}, r...)
+	for j := 1; len(s) < d; j++ {
+		block := make([]byte, 16)
+		binary.BigEndian.PutUint64(block[8:], uint64(j))
+		for k := range block {
+			block[k] ^= r[k]
+		}
+		c.block.Encrypt(block, block)
+		s = append(s, block...)
+	}
+	return s[:d]
+}
@@ -0,0 +1,107 @@
+package fpe
+
+import (
+	"crypto/cipher"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+const (
+	ff3Rounds = 8
+	//FF3-1 tweak is 56 bits
+	FF31TweakSize = 7
+)
+
+//FF3-1 of NIST SP 800-38G Rev1
+type FF31 struct {
+	block     cipher.Block
+	radix     int
+	maxLength int
+}
+
+func NewFF31(key []byte, radix int) (*FF31, error) {
+	if err := validateRadix(radix); err != nil {
+		return nil, err
+	}
+	//FF3 uses the key in reversed byte order
+	block, err := newBlock(reverseBytes(key))
+	if err != nil {
+		return nil, err
+	}
+	maxLength := 2 * int(math.Floor(96/math.Log2(float64(radix))))
+	return &FF31{block: block, radix: radix, maxLength: maxLength}, nil
+}
+
+func (c *FF31) Encrypt(x []int, tweak []byte) ([]int, error) {
+	left, right, err := splitFF31Tweak(tweak)
+	if err != nil {
+		return nil, err
+	}
+	return c.cipher(x, left, right, true)
+}
+
+func (c *FF31) Decrypt(x []int, tweak []byte) ([]int, error) {
+	left, right, err := splitFF31Tweak(tweak)
+	if err != nil {
+		return nil, err
+	}
+	return c.cipher(x, left, right, false)
+}
+
+//TL = T[0..27] || 0000, TR = T[32..55] || T[28..31] || 0000
+func splitFF31Tweak(tweak []byte) ([]byte, []byte, error) {
+	if len(tweak) != FF31TweakSize {
+		return nil, nil, fmt.Errorf("tweak must be %v bytes", FF31TweakSize)
+	}
+	left := []byte{tweak[0], tweak[1], tweak[2], tweak[3] & 0xF0}
+	right := []byte{tweak[4], tweak[5], tweak[6], (tweak[3] & 0x0F) << 4}
+	return left, right, nil
+}
+
+func (c *FF31) cipher(x []int, tweakLeft, tweakRight []byte, encrypt bool) ([]int, error) {
+	if err := validateNumerals(x, c.radix, c.maxLength); err != nil {
+		return nil, err
+	}
+	n := len(x)
+	u := (n + 1) / 2
+	v := n - u
+	a := append([]int{}, x[:u]...)
+	b := append([]int{}, x[u:]...)
+
+	modU, modV := pow(c.radix, u), pow(c.radix, v)
+	p := make([]byte, 16)
+	for round := 0; round < ff3Rounds; round++ {
+		i := round
+		if !encrypt {
+			i = ff3Rounds - 1 - round
+		}
+		m, modulus, w := u, modU, tweakRight
+		if i%2 == 1 {
+			m, modulus, w = v, modV, tweakLeft
+		}
+
+		//round function is applied to B when encrypting and to A when decrypting
+		source := b
+		if !encrypt {
+			source = a
+		}
+		copy(p, w)
+		p[3] ^= byte(i)
+		putBytes(p[4:], num(reverse(source), c.radix))
+		s := reverseBytes(p)
+		c.block.Encrypt(s, s)
+		y := new(big.Int).SetBytes(reverseBytes(s))
+
+		if encrypt {
+			value := num(reverse(a), c.radix)
+			value.Add(value, y).Mod(value, modulus)
+			a, b = b, reverse(str(value, c.radix, m))
+		} else {
+			value := num(reverse(b), c.radix)
+			value.Sub(value, y).Mod(value, modulus)
+			b, a = a, reverse(str(value, c.radix, m))
+		}
+	}
+	return append(a, b...), nil
+}
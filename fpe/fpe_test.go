@@ -0,0 +1,204 @@
+package fpe
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const (
+	ff1Key128 = "2B7E151628AED2A6ABF7158809CF4F3C"
+	ff1Key192 = "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F"
+	ff1Key256 = "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94"
+	ff3Key128 = "EF4359D8D580AA4F7F036D6F04FC6A94"
+	ff3Key192 = "EF4359D8D580AA4F7F036D6F04FC6A942B7E151628AED2A6"
+	ff3Key256 = "EF4359D8D580AA4F7F036D6F04FC6A942B7E151628AED2A6ABF7158809CF4F3C"
+)
+
+type sampleVector struct {
+	key        string
+	radix      int
+	tweak      string
+	plaintext  string
+	ciphertext string
+}
+
+//NIST SP 800-38G FF1 samples
+var ff1Samples = []sampleVector{
+	{ff1Key128, 10, "", "0123456789", "2433477484"},
+	{ff1Key128, 10, "39383736353433323130", "0123456789", "6124200773"},
+	{ff1Key128, 36, "3737373770717273373737", "0123456789abcdefghi", "a9tv40mll9kdu509eum"},
+	{ff1Key192, 10, "", "0123456789", "2830668132"},
+	{ff1Key192, 10, "39383736353433323130", "0123456789", "2496655549"},
+	{ff1Key192, 36, "3737373770717273373737", "0123456789abcdefghi", "xbj3kv35jrawxv32ysr"},
+	{ff1Key256, 10, "", "0123456789", "6657667009"},
+	{ff1Key256, 10, "39383736353433323130", "0123456789", "1001623463"},
+	{ff1Key256, 36, "3737373770717273373737", "0123456789abcdefghi", "xs8a0azh2avyalyzuwd"},
+}
+
+//NIST SP 800-38G FF3 samples, FF3-1 differs only in the tweak size, so they are checked with 64-bit tweak halves
+var ff3Samples = []sampleVector{
+	{ff3Key128, 10, "D8E7920AFA330A73", "890121234567890000", "750918814058654607"},
+	{ff3Key128, 10, "9A768A92F60E12D8", "890121234567890000", "018989839189395384"},
+	{ff3Key128, 10, "D8E7920AFA330A73", "89012123456789000000789000000", "48598367162252569629397416226"},
+	{ff3Key128, 10, "0000000000000000", "89012123456789000000789000000", "34695224821734535122613701434"},
+	{ff3Key128, 26, "9A768A92F60E12D8", "0123456789abcdefghi", "g2pk40i992fn20cjakb"},
+	{ff3Key192, 10, "D8E7920AFA330A73", "890121234567890000", "646965393875028755"},
+	{ff3Key192, 10, "9A768A92F60E12D8", "890121234567890000", "961610514491424446"},
+	{ff3Key192, 10, "D8E7920AFA330A73", "89012123456789000000789000000", "53048884065350204541786380807"},
+	{ff3Key192, 10, "0000000000000000", "89012123456789000000789000000", "98083802678820389295041483512"},
+	{ff3Key192, 26, "9A768A92F60E12D8", "0123456789abcdefghi", "i0ihe2jfj7a9opf9p88"},
+	{ff3Key256, 10, "D8E7920AFA330A73", "890121234567890000", "922011205562777495"},
+	{ff3Key256, 10, "9A768A92F60E12D8", "890121234567890000", "504149865578056140"},
+	{ff3Key256, 10, "D8E7920AFA330A73", "89012123456789000000789000000", "04344343235792599165734622699"},
+	{ff3Key256, 10, "0000000000000000", "89012123456789000000789000000", "30859239999374053872365555822"},
+	{ff3Key256, 26, "9A768A92F60E12D8", "0123456789abcdefghi", "p0b2godfja9bhb7bk38"},
+}
+
+func toNumerals(t *testing.T, value string, radix int) []int {
+	numerals := make([]int, len(value))
+	for i, r := range value {
+		numeral, err := strconv.ParseInt(string(r), radix, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		numerals[i] = int(numeral)
+	}
+	return numerals
+}
+
+func fromNumerals(numerals []int, radix int) string {
+	var builder strings.Builder
+	for _, numeral := range numerals {
+		builder.WriteString(strconv.FormatInt(int64(numeral), radix))
+	}
+	return builder.String()
+}
+
+func decodeHex(t *testing.T, value string) []byte {
+	bytes, err := hex.DecodeString(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes
+}
+
+func TestFF1Samples(t *testing.T) {
+	for _, sample := range ff1Samples {
+		cipher, err := NewFF1(decodeHex(t, sample.key), sample.radix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tweak := decodeHex(t, sample.tweak)
+		encrypted, err := cipher.Encrypt(toNumerals(t, sample.plaintext, sample.radix), tweak)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result := fromNumerals(encrypted, sample.radix); result != sample.ciphertext {
+			t.Errorf("encrypt %v: got %v, expected %v", sample, result, sample.ciphertext)
+		}
+		decrypted, err := cipher.Decrypt(toNumerals(t, sample.ciphertext, sample.radix), tweak)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result := fromNumerals(decrypted, sample.radix); result != sample.plaintext {
+			t.Errorf("decrypt %v: got %v, expected %v", sample, result, sample.plaintext)
+		}
+	}
+}
+
+func TestFF3Samples(t *testing.T) {
+	for _, sample := range ff3Samples {
+		cipher, err := NewFF31(decodeHex(t, sample.key), sample.radix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tweak := decodeHex(t, sample.tweak)
+		encrypted, err := cipher.cipher(toNumerals(t, sample.plaintext, sample.radix), tweak[:4], tweak[4:], true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result := fromNumerals(encrypted, sample.radix); result != sample.ciphertext {
+			t.Errorf("encrypt %v: got %v, expected %v", sample, result, sample.ciphertext)
+		}
+		decrypted, err := cipher.cipher(toNumerals(t, sample.ciphertext, sample.radix), tweak[:4], tweak[4:], false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result := fromNumerals(decrypted, sample.radix); result != sample.plaintext {
+			t.Errorf("decrypt %v: got %v, expected %v", sample, result, sample.plaintext)
+		}
+	}
+}
+
+func TestFF31Tweak(t *testing.T) {
+	left, right, err := splitFF31Tweak(decodeHex(t, "D8E7920AFA330A"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(left) != "d8e79200" || hex.EncodeToString(right) != "fa330aa0" {
+		t.Errorf("unexpected tweak halves %x, %x", left, right)
+	}
+	if _, _, err = splitFF31Tweak(decodeHex(t, "D8E7920AFA330A73")); err == nil {
+		t.Errorf("64-bit tweak must be rejected by FF3-1")
+	}
+}
+
+//there are no radix 2 samples, binary ciphers are used by the fpe strategy, so they are checked by round trips
+func TestBinaryRoundTrip(t *testing.T) {
+	ff1, err := NewFF1(decodeHex(t, ff1Key128), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ff31, err := NewFF31(decodeHex(t, ff3Key128), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tweaks := map[Cipher][]byte{ff1: decodeHex(t, "39383736353433323130"), ff31: decodeHex(t, "D8E7920AFA330A")}
+	random := rand.New(rand.NewSource(1))
+	for cipher, tweak := range tweaks {
+		for _, length := range []int{MinLength(2), 33, 64, 150} {
+			if cipher == ff31 && length > ff31.maxLength {
+				continue
+			}
+			for i := 0; i < 20; i++ {
+				bits := make([]int, length)
+				for j := range bits {
+					bits[j] = random.Intn(2)
+				}
+				encrypted, err := cipher.Encrypt(bits, tweak)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(encrypted) != length {
+					t.Fatalf("length %v changed to %v", length, len(encrypted))
+				}
+				decrypted, err := cipher.Decrypt(encrypted, tweak)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if fromNumerals(decrypted, 2) != fromNumerals(bits, 2) {
+					t.Errorf("round trip of %v failed: %v", bits, decrypted)
+				}
+			}
+		}
+	}
+}
+
+func TestValidateNumerals(t *testing.T) {
+	cipher, err := NewFF1(decodeHex(t, ff1Key128), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cipher.Encrypt([]int{1, 2, 3, 4, 5}, nil); err == nil {
+		t.Errorf("domain under 10^6 must be rejected")
+	}
+	if _, err = cipher.Encrypt([]int{1, 2, 3, 4, 5, 10}, nil); err == nil {
+		t.Errorf("numeral out of radix must be rejected")
+	}
+	if MinLength(2) != 20 || MinLength(10) != 6 || MinLength(36) != 4 {
+		t.Errorf("unexpected min lengths %v, %v, %v", MinLength(2), MinLength(10), MinLength(36))
+	}
+}
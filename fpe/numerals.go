@@ -0,0 +1,118 @@
+package fpe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+const (
+	minRadix = 2
+	maxRadix = 1 << 16
+	//NIST SP 800-38G Rev1 requires radix^minLength >= 1000000
+	MinDomainSize = 1000000
+)
+
+//FF1 and FF3-1 encrypt numeral strings of the radix
+type Cipher interface {
+	Encrypt(x []int, tweak []byte) ([]int, error)
+	Decrypt(x []int, tweak []byte) ([]int, error)
+}
+
+func newBlock(key []byte) (cipher.Block, error) {
+	switch len(key) {
+	case 16, 24, 32:
+		return aes.NewCipher(key)
+	default:
+		return nil, fmt.Errorf("key must be 16, 24 or 32 bytes")
+	}
+}
+
+func validateRadix(radix int) error {
+	if radix < minRadix || radix > maxRadix {
+		return fmt.Errorf("radix must be in [%v, %v]", minRadix, maxRadix)
+	}
+	return nil
+}
+
+//returns min length of numeral string which is secure for the radix
+func MinLength(radix int) int {
+	length := int(math.Ceil(math.Log(MinDomainSize) / math.Log(float64(radix))))
+	if length < 2 {
+		return 2
+	}
+	return length
+}
+
+func validateNumerals(x []int, radix, maxLength int) error {
+	if len(x) < MinLength(radix) {
+		return fmt.Errorf("value is too short, min length for radix %v is %v", radix, MinLength(radix))
+	}
+	if maxLength > 0 && len(x) > maxLength {
+		return fmt.Errorf("value is too long, max length for radix %v is %v", radix, maxLength)
+	}
+	for _, numeral := range x {
+		if numeral < 0 || numeral >= radix {
+			return fmt.Errorf("numeral %v is out of radix %v", numeral, radix)
+		}
+	}
+	return nil
+}
+
+//NUM_radix(X), the first numeral is the most significant
+func num(x []int, radix int) *big.Int {
+	result := new(big.Int)
+	bigRadix := big.NewInt(int64(radix))
+	for _, numeral := range x {
+		result.Mul(result, bigRadix)
+		result.Add(result, big.NewInt(int64(numeral)))
+	}
+	return result
+}
+
+//STR^m_radix(x)
+func str(x *big.Int, radix, m int) []int {
+	result := make([]int, m)
+	value := new(big.Int).Set(x)
+	bigRadix := big.NewInt(int64(radix))
+	remainder := new(big.Int)
+	for i := m - 1; i >= 0; i-- {
+		value.DivMod(value, bigRadix, remainder)
+		result[i] = int(remainder.Int64())
+	}
+	return result
+}
+
+func pow(radix, m int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(int64(radix)), big.NewInt(int64(m)), nil)
+}
+
+func reverse(x []int) []int {
+	result := make([]int, len(x))
+	for i, numeral := range x {
+		result[len(x)-1-i] = numeral
+	}
+	return result
+}
+
+func reverseBytes(x []byte) []byte {
+	result := make([]byte, len(x))
+	for i, b := range x {
+		result[len(x)-1-i] = b
+	}
+	return result
+}
+
+//writes x to the fixed size big-endian byte string
+func putBytes(destination []byte, x *big.Int) {
+	for i := range destination {
+		destination[i] = 0
+	}
+	bytes := x.Bytes()
+	if len(bytes) > len(destination) {
+		bytes = bytes[len(bytes)-len(destination):]
+	}
+	copy(destination[len(destination)-len(bytes):], bytes)
+}
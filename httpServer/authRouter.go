@@ -0,0 +1,162 @@
+package httpServer
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"obfuscator/auth"
+	"strings"
+	"time"
+)
+
+const (
+	principalKey          = "principal"
+	loginParam            = "login"
+	tokenIdParam          = "tokenId"
+	bearerPrefix          = "Bearer "
+	authenticateHeader    = "WWW-Authenticate"
+	authenticateHeaderVal = "Basic realm=\"Authorization Required\""
+)
+
+func authRouter(router gin.RouterGroup) {
+	router.GET("/users", requireRole(auth.AdminRole), listUsers)
+
+	router.POST("/users", requireRole(auth.AdminRole), saveUser)
+
+	router.DELETE("/users/:"+loginParam, requireRole(auth.AdminRole), deleteUser)
+
+	router.GET("/tokens", requireRole(auth.ViewerRole), listTokens)
+
+	router.POST("/tokens", requireRole(auth.ViewerRole), createToken)
+
+	router.DELETE("/tokens/:"+tokenIdParam, requireRole(auth.ViewerRole), revokeToken)
+}
+
+//accepts basic auth with user credentials or bearer token
+func authenticate(c *gin.Context) {
+	var principal auth.Principal
+	authenticated := false
+	header := c.GetHeader("Authorization")
+	if strings.HasPrefix(header, bearerPrefix) {
+		principal, authenticated = auth.AuthenticateToken(strings.TrimPrefix(header, bearerPrefix))
+	} else if login, password, ok := c.Request.BasicAuth(); ok {
+		principal, authenticated = auth.AuthenticateUser(login, password)
+	}
+	if !authenticated {
+		c.Header(authenticateHeader, authenticateHeaderVal)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "Authentication required",
+		})
+		return
+	}
+	c.Set(gin.AuthUserKey, principal.Login)
+	c.Set(principalKey, principal)
+	c.Next()
+}
+
+func requireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.HasRole(getPrincipal(c).Role, role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{
+				Error: "Role " + role + " is required",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+func getPrincipal(c *gin.Context) auth.Principal {
+	principal, _ := c.Get(principalKey)
+	result, _ := principal.(auth.Principal)
+	return result
+}
+
+func listUsers(c *gin.Context) {
+	users, err := auth.ListUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+func saveUser(c *gin.Context) {
+	var request SaveUserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	err := auth.SaveUser(request.Login, request.Password, request.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessfulResponse{
+		Status: "OK",
+	})
+}
+
+func deleteUser(c *gin.Context) {
+	err := auth.DeleteUser(c.Param(loginParam))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessfulResponse{
+		Status: "OK",
+	})
+}
+
+func listTokens(c *gin.Context) {
+	tokens, err := auth.ListTokens(getPrincipal(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+func createToken(c *gin.Context) {
+	var request CreateTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	ttl := time.Duration(request.TtlHours) * time.Hour
+	value, token, err := auth.CreateToken(getPrincipal(c), request.Role, ttl)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, TokenResponse{
+		Token: token,
+		Value: value,
+	})
+}
+
+func revokeToken(c *gin.Context) {
+	err := auth.RevokeToken(getPrincipal(c), c.Param(tokenIdParam))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessfulResponse{
+		Status: "OK",
+	})
+}
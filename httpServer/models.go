@@ -1,5 +1,11 @@
 package httpServer
 
+import (
+	"obfuscator/auth"
+	"obfuscator/obfuscating"
+	"obfuscator/policy"
+)
+
 type ErrorResponse struct {
 	Error string
 }
@@ -12,3 +18,27 @@ type ObfuscationResponse struct {
 	SuccessfulResponse
 	ProcessId string
 }
+
+type PolicyDraftResponse struct {
+	Policy      policy.Policy
+	Suggestions map[string][]obfuscating.ColumnSuggestion
+}
+
+type SaveUserRequest struct {
+	Login    string `binding:"required"`
+	Password string `binding:"required"`
+	Role     string `binding:"required"`
+}
+
+type CreateTokenRequest struct {
+	//user's role if empty
+	Role string
+	//default from config if 0
+	TtlHours int
+}
+
+type TokenResponse struct {
+	auth.Token
+	//isn't stored, so can't be got again
+	Value string
+}
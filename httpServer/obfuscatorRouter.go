@@ -1,23 +1,40 @@
 package httpServer
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"obfuscator/auth"
 	"obfuscator/obfuscating"
+	"obfuscator/policy"
 )
 
 const (
 	processIdParam = "processId"
+	formatQuery    = "format"
+	jsonFormat     = "json"
+	markdownFormat = "markdown"
+	htmlFormat     = "html"
 )
 
 func obfuscatorRouter(router gin.RouterGroup) {
-	router.POST("/schema-info", getSchemaInfo)
+	router.POST("/schema-info", requireRole(auth.ViewerRole), getSchemaInfo)
 
-	router.POST("/obfuscate", obfuscate)
+	router.POST("/suggest-model", requireRole(auth.OperatorRole), suggestModel)
 
-	router.GET("/status/:"+processIdParam, getProcessStatus)
+	router.POST("/obfuscate", requireRole(auth.OperatorRole), obfuscate)
 
-	router.POST("/empty-progress-ctx", emptyProgressCtx)
+	router.POST("/preview", requireRole(auth.OperatorRole), preview)
+
+	router.GET("/status/:"+processIdParam, requireRole(auth.ViewerRole), getProcessStatus)
+
+	router.GET("/jobs/:"+processIdParam+"/report", requireRole(auth.ViewerRole), getAuditReport)
+
+	router.POST("/empty-progress-ctx", requireRole(auth.AdminRole), emptyProgressCtx)
+
+	router.POST("/reidentify", requireRole(auth.AdminRole), reidentify)
+
+	router.GET("/reidentifications", requireRole(auth.AdminRole), getReidentifications)
 }
 
 func obfuscate(c *gin.Context) {
@@ -29,15 +46,41 @@ func obfuscate(c *gin.Context) {
 		return
 	}
 
-	err := obfuscating.ValidateObfuscationModel(request.Model, request.Origin)
+	if !resolveConnections(c, &request.Origin, &request.Destination) {
+		return
+	}
+
+	model, policyVersion, status, err := resolveModel(request.Model, request.Policy, request.PolicyVersion, request.Origin)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+		c.JSON(status, ErrorResponse{
 			Error: err.Error(),
 		})
 		return
 	}
+	request.Model = model
+	request.PolicyVersion = policyVersion
 
-	processId, err := obfuscating.InitProcess(len(request.Model))
+	err = obfuscating.ValidateConflictModes(request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	if request.DryRun {
+		plan, err := obfuscating.PlanObfuscation(request)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusOK, plan)
+		return
+	}
+
+	processId, err := obfuscating.InitProcess(request, c.GetString(gin.AuthUserKey))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error: err.Error(),
@@ -45,7 +88,7 @@ func obfuscate(c *gin.Context) {
 		return
 	}
 
-	go obfuscating.ObfuscateSchema(request.Model, processId, request.Origin, request.Destination)
+	go obfuscating.ObfuscateSchema(request, processId)
 	c.JSON(http.StatusOK, ObfuscationResponse{
 		SuccessfulResponse: SuccessfulResponse{
 			"Obfuscation was started.",
@@ -54,6 +97,63 @@ func obfuscate(c *gin.Context) {
 	})
 }
 
+func preview(c *gin.Context) {
+	var request obfuscating.PreviewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	if !resolveConnections(c, &request.Origin) {
+		return
+	}
+
+	model, _, status, err := resolveModel(request.Model, request.Policy, request.PolicyVersion, request.Origin)
+	if err != nil {
+		c.JSON(status, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	result, err := obfuscating.PreviewTable(model, request.Table, request.Limit, request.MaskOriginals, request.Origin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+//returns validated model built from policy with its version if policy is set or given model otherwise
+func resolveModel(model map[string][]obfuscating.Column, policyName string, policyVersion int,
+	origin obfuscating.ConnectionInfo) (map[string][]obfuscating.Column, int, int, error) {
+	if policyName != "" {
+		p, version, err := policy.Load(policyName, policyVersion)
+		if err != nil {
+			return nil, 0, http.StatusBadRequest, err
+		}
+		model, err = policy.BuildModel(p, origin)
+		if err != nil {
+			return nil, 0, http.StatusInternalServerError, err
+		}
+		return model, version, http.StatusOK, nil
+	}
+
+	if model == nil {
+		return nil, 0, http.StatusBadRequest, fmt.Errorf("either Model or Policy is required")
+	}
+	err := obfuscating.ValidateObfuscationModel(model, origin)
+	if err != nil {
+		return nil, 0, http.StatusInternalServerError, err
+	}
+	return model, 0, http.StatusOK, nil
+}
+
 func getSchemaInfo(c *gin.Context) {
 	var request obfuscating.ConnectionInfo
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -63,6 +163,10 @@ func getSchemaInfo(c *gin.Context) {
 		return
 	}
 
+	if !resolveConnections(c, &request) {
+		return
+	}
+
 	result, err := obfuscating.GetSchemaInfo(request)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
@@ -74,6 +178,30 @@ func getSchemaInfo(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+func suggestModel(c *gin.Context) {
+	var request obfuscating.ConnectionInfo
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	if !resolveConnections(c, &request) {
+		return
+	}
+
+	result, err := obfuscating.SuggestModel(request)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 func getProcessStatus(c *gin.Context) {
 	processId := c.Param(processIdParam)
 	result, exists := obfuscating.GetProcessCtx(processId)
@@ -86,9 +214,76 @@ func getProcessStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+//format query can be json (default), markdown or html
+func getAuditReport(c *gin.Context) {
+	processId := c.Param(processIdParam)
+	report, exists := obfuscating.GetAuditReport(processId)
+	if !exists {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Entry with this process id doesn't exist",
+		})
+		return
+	}
+
+	switch c.DefaultQuery(formatQuery, jsonFormat) {
+	case jsonFormat:
+		c.Header("Content-Disposition", "attachment; filename=report-"+processId+".json")
+		c.JSON(http.StatusOK, report)
+	case markdownFormat:
+		c.Header("Content-Disposition", "attachment; filename=report-"+processId+".md")
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(report.Markdown()))
+	case htmlFormat:
+		html, err := report.Html()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: err.Error(),
+			})
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename=report-"+processId+".html")
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Unknown report format",
+		})
+	}
+}
+
 func emptyProgressCtx(c *gin.Context) {
 	obfuscating.EmptyProgressCtx()
 	c.JSON(http.StatusOK, SuccessfulResponse{
 		Status: "OK",
 	})
 }
+
+func reidentify(c *gin.Context) {
+	var request obfuscating.ReidentifyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	result, err := obfuscating.Reidentify(request, getPrincipal(c).Login)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func getReidentifications(c *gin.Context) {
+	result, err := obfuscating.GetReidentificationRecords()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
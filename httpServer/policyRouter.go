@@ -0,0 +1,174 @@
+package httpServer
+
+import (
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"obfuscator/auth"
+	"obfuscator/config"
+	"obfuscator/obfuscating"
+	"obfuscator/policy"
+	"strconv"
+)
+
+const (
+	policyNameParam    = "name"
+	policyVersionQuery = "version"
+	policyCommentQuery = "comment"
+)
+
+func policyRouter(router gin.RouterGroup) {
+	router.GET("/policies", requireRole(auth.ViewerRole), listPolicies)
+
+	router.POST("/policies", requireRole(auth.AdminRole), savePolicy)
+
+	router.GET("/policies/:"+policyNameParam, requireRole(auth.ViewerRole), getPolicy)
+
+	router.GET("/policies/:"+policyNameParam+"/history", requireRole(auth.ViewerRole), getPolicyHistory)
+
+	router.POST("/policies/:"+policyNameParam+"/validate", requireRole(auth.OperatorRole), validatePolicy)
+
+	router.POST("/policies/:"+policyNameParam+"/draft", requireRole(auth.OperatorRole), draftPolicy)
+}
+
+func listPolicies(c *gin.Context) {
+	names, err := policy.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, names)
+}
+
+//accepts policy in YAML or JSON format
+func savePolicy(c *gin.Context) {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	p, err := policy.Parse(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	info, err := policy.Save(p, c.GetString(gin.AuthUserKey), c.Query(policyCommentQuery))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+func getPolicy(c *gin.Context) {
+	version, err := getPolicyVersionQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	p, _, err := policy.Load(c.Param(policyNameParam), version)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func getPolicyHistory(c *gin.Context) {
+	history, err := policy.History(c.Param(policyNameParam))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+//resolves policy against live schema and returns resulting model
+func validatePolicy(c *gin.Context) {
+	var request obfuscating.ConnectionInfo
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	if !resolveConnections(c, &request) {
+		return
+	}
+	version, err := getPolicyVersionQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	p, _, err := policy.Load(c.Param(policyNameParam), version)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	model, err := policy.BuildModel(p, request)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, model)
+}
+
+//drafts policy from detected personal data, draft isn't saved
+func draftPolicy(c *gin.Context) {
+	var request obfuscating.ConnectionInfo
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	if !resolveConnections(c, &request) {
+		return
+	}
+
+	suggestions, err := obfuscating.SuggestModel(request)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	minConfidence := config.GetConfig().Detection.MinConfidence
+	c.JSON(http.StatusOK, PolicyDraftResponse{
+		Policy:      policy.Draft(c.Param(policyNameParam), suggestions, minConfidence),
+		Suggestions: suggestions,
+	})
+}
+
+func getPolicyVersionQuery(c *gin.Context) (int, error) {
+	versionStr := c.Query(policyVersionQuery)
+	if versionStr == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(versionStr)
+}
@@ -0,0 +1,80 @@
+package httpServer
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"obfuscator/auth"
+	"obfuscator/obfuscating"
+	"obfuscator/profile"
+)
+
+const (
+	profileNameParam = "name"
+)
+
+func profileRouter(router gin.RouterGroup) {
+	router.GET("/profiles", requireRole(auth.ViewerRole), listProfiles)
+
+	router.POST("/profiles", requireRole(auth.AdminRole), saveProfile)
+
+	router.DELETE("/profiles/:"+profileNameParam, requireRole(auth.AdminRole), deleteProfile)
+}
+
+func listProfiles(c *gin.Context) {
+	profiles, err := profile.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, profiles)
+}
+
+//Profile field of connection info is used as profile name
+func saveProfile(c *gin.Context) {
+	var request obfuscating.ConnectionInfo
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	err := profile.Save(request.Profile, request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessfulResponse{
+		Status: "OK",
+	})
+}
+
+func deleteProfile(c *gin.Context) {
+	err := profile.Delete(c.Param(profileNameParam))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessfulResponse{
+		Status: "OK",
+	})
+}
+
+//fills connections from profiles, writes error response and returns false if it's failed
+func resolveConnections(c *gin.Context, connInfos ...*obfuscating.ConnectionInfo) bool {
+	for _, connInfo := range connInfos {
+		err := profile.Resolve(connInfo)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: err.Error(),
+			})
+			return false
+		}
+	}
+	return true
+}
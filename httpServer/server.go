@@ -2,31 +2,30 @@ package httpServer
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"log"
-)
-
-const (
-	AdminLogin = "admin"
+	"obfuscator/auth"
 )
 
 func InitServer() error {
 	log.Println("Initializing http-server STARTED")
 
-	router := gin.Default()
-
-	randomUuid, err := uuid.NewRandom()
+	passwordPath, err := auth.InitDefaultAdmin()
 	if err != nil {
 		return err
 	}
-	adminPassword := randomUuid.String()
-	log.Println("Admin password: ", adminPassword)
+	if passwordPath != "" {
+		//the password isn't logged, it's valid until it's changed via API or users are configured
+		log.Printf("Warning: no users are configured, password of %v is generated to %v", auth.AdminLogin,
+			passwordPath)
+	}
 
-	authorized := router.Group("/", gin.BasicAuth(gin.Accounts{
-		AdminLogin: adminPassword,
-	}))
+	router := gin.Default()
+	authorized := router.Group("/", authenticate)
 
 	obfuscatorRouter(*authorized)
+	policyRouter(*authorized)
+	authRouter(*authorized)
+	profileRouter(*authorized)
 
 	err = router.Run()
 	if err != nil {
@@ -1,10 +1,21 @@
 package main
 
 import (
+	"fmt"
+	"obfuscator/cli"
 	"obfuscator/httpServer"
+	"os"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		err := cli.Run(os.Args[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	err := httpServer.InitServer()
 	if err != nil {
 		panic(err)
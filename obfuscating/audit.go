@@ -0,0 +1,239 @@
+package obfuscating
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"html/template"
+	"obfuscator/encoding"
+	"strings"
+	"time"
+)
+
+//job statuses
+const (
+	RunningStatus  = "running"
+	FinishedStatus = "finished"
+	FailedStatus   = "failed"
+)
+
+//reasons of copying column verbatim
+const (
+	primaryKeyReason      = "primary key"
+	uniqueKeyReason       = "unique key"
+	foreignKeyReason      = "foreign key"
+	indexKeyReason        = "index key"
+	unsupportedTypeReason = "unsupported type"
+	notSelectedReason     = "not selected in model"
+)
+
+type ColumnAudit struct {
+	Column      string
+	Type        string
+	Transformed bool
+	Strategy    string `json:",omitempty"`
+	//why column was copied verbatim
+	Reason string `json:",omitempty"`
+	Rows   int64
+	Nulls  int64
+	//values which were copied verbatim because encoding was failed
+	Fallbacks int64
+	LastError string `json:",omitempty"`
+}
+
+type TableAudit struct {
+	Table   string
+	Columns []ColumnAudit
+}
+
+type AuditReport struct {
+	ProcessId     string
+	Status        string
+	Error         string `json:",omitempty"`
+	StartedBy     string
+	Policy        string `json:",omitempty"`
+	PolicyVersion int    `json:",omitempty"`
+	Started       time.Time
+	Finished      *time.Time `json:",omitempty"`
+	Tables        []TableAudit
+	Verification  *VerificationReport `json:",omitempty"`
+	LeakScan      *LeakReport         `json:",omitempty"`
+}
+
+func GetAuditReport(processId string) (AuditReport, bool) {
+	progressMutex.RLock()
+	defer progressMutex.RUnlock()
+	entry, exists := progressCtx[processId]
+	if !exists {
+		return AuditReport{}, false
+	}
+
+	report := AuditReport{
+		ProcessId:     entry.ProcessId,
+		Status:        RunningStatus,
+		Error:         entry.Error,
+		StartedBy:     entry.StartedBy,
+		Policy:        entry.Policy,
+		PolicyVersion: entry.PolicyVersion,
+		Started:       entry.Started,
+		Finished:      entry.Finished,
+		Verification:  entry.Verification,
+		LeakScan:      entry.LeakScan,
+	}
+	if entry.Error != "" {
+		report.Status = FailedStatus
+	} else if entry.Finished != nil {
+		report.Status = FinishedStatus
+	}
+	for _, table := range getSortedKeys(entry.model) {
+		tableAudit := TableAudit{Table: table}
+		for _, column := range entry.model[table] {
+			tableAudit.Columns = append(tableAudit.Columns, entry.audit[table][column.Name])
+		}
+		report.Tables = append(report.Tables, tableAudit)
+	}
+	return report, true
+}
+
+//collects column audits with reasons of copying verbatim taken from origin schema
+func initAudit(db *sql.DB, model map[string][]Column) (map[string]map[string]*ColumnAudit, error) {
+	audit := make(map[string]map[string]*ColumnAudit)
+	for table, columns := range model {
+		rawColumns, err := showColumns(db, table)
+		if err != nil {
+			return nil, err
+		}
+		foreignKeyColumns, err := getForeignKeyColumns(db, table)
+		if err != nil {
+			return nil, err
+		}
+		keys := make(map[string]string)
+		for _, rawColumn := range rawColumns {
+			keys[rawColumn.Field] = rawColumn.Key
+		}
+
+		audit[table] = make(map[string]*ColumnAudit)
+		for _, column := range columns {
+			columnAudit := &ColumnAudit{
+				Column:      column.Name,
+				Type:        column.Type,
+				Transformed: column.NeedToObfuscate && column.Strategy != encoding.KeepStrategy,
+			}
+			if columnAudit.Transformed {
+				columnAudit.Strategy = column.Strategy
+				if columnAudit.Strategy == encoding.DefaultStrategy {
+					columnAudit.Strategy = "default"
+				}
+			} else {
+				columnAudit.Reason = getVerbatimReason(column, keys[column.Name], foreignKeyColumns[column.Name])
+			}
+			audit[table][column.Name] = columnAudit
+		}
+	}
+	return audit, nil
+}
+
+func getVerbatimReason(column Column, key string, isForeignKey bool) string {
+	switch {
+	case column.IsPrimaryKey || key == primaryKeyWord:
+		return primaryKeyReason
+	case key == uniqueKeyWord && isForeignKey:
+		return foreignKeyReason
+	case key == uniqueKeyWord:
+		return uniqueKeyReason
+	case key != "":
+		return indexKeyReason
+	case !encoding.IsSupportedType(column.Type):
+		return unsupportedTypeReason
+	default:
+		return notSelectedReason
+	}
+}
+
+func (r AuditReport) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Obfuscation audit report %v\n\n", r.ProcessId)
+	fmt.Fprintf(&sb, "| | |\n|---|---|\n")
+	fmt.Fprintf(&sb, "| Status | %v |\n", r.Status)
+	if r.Error != "" {
+		fmt.Fprintf(&sb, "| Error | %v |\n", escapeMarkdown(r.Error))
+	}
+	fmt.Fprintf(&sb, "| Started by | %v |\n", escapeMarkdown(r.StartedBy))
+	if r.Policy != "" {
+		fmt.Fprintf(&sb, "| Policy | %v, version %v |\n", r.Policy, r.PolicyVersion)
+	}
+	fmt.Fprintf(&sb, "| Started | %v |\n", r.Started.Format(time.RFC3339))
+	if r.Finished != nil {
+		fmt.Fprintf(&sb, "| Finished | %v |\n", r.Finished.Format(time.RFC3339))
+	}
+	if r.Verification != nil {
+		fmt.Fprintf(&sb, "| Verification | %v |\n", passedToString(r.Verification.Passed))
+	}
+	if r.LeakScan != nil {
+		fmt.Fprintf(&sb, "| Leak scan | %v |\n", passedToString(r.LeakScan.Passed))
+	}
+
+	for _, table := range r.Tables {
+		fmt.Fprintf(&sb, "\n## %v\n\n", table.Table)
+		fmt.Fprintf(&sb, "| Column | Type | Transformation | Rows | NULLs | Fallbacks |\n")
+		fmt.Fprintf(&sb, "|---|---|---|---|---|---|\n")
+		for _, column := range table.Columns {
+			fmt.Fprintf(&sb, "| %v | %v | %v | %v | %v | %v |\n", column.Column, column.Type,
+				getTransformationDescription(column), column.Rows, column.Nulls, column.Fallbacks)
+		}
+	}
+	return sb.String()
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"transformation": getTransformationDescription,
+	"passed":         passedToString,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Obfuscation audit report {{.ProcessId}}</title></head>
+<body>
+<h1>Obfuscation audit report {{.ProcessId}}</h1>
+<table border="1">
+<tr><th>Status</th><td>{{.Status}}</td></tr>
+{{if .Error}}<tr><th>Error</th><td>{{.Error}}</td></tr>{{end}}
+<tr><th>Started by</th><td>{{.StartedBy}}</td></tr>
+{{if .Policy}}<tr><th>Policy</th><td>{{.Policy}}, version {{.PolicyVersion}}</td></tr>{{end}}
+<tr><th>Started</th><td>{{.Started}}</td></tr>
+{{if .Finished}}<tr><th>Finished</th><td>{{.Finished}}</td></tr>{{end}}
+{{if .Verification}}<tr><th>Verification</th><td>{{passed .Verification.Passed}}</td></tr>{{end}}
+{{if .LeakScan}}<tr><th>Leak scan</th><td>{{passed .LeakScan.Passed}}</td></tr>{{end}}
+</table>
+{{range .Tables}}
+<h2>{{.Table}}</h2>
+<table border="1">
+<tr><th>Column</th><th>Type</th><th>Transformation</th><th>Rows</th><th>NULLs</th><th>Fallbacks</th></tr>
+{{range .Columns}}<tr><td>{{.Column}}</td><td>{{.Type}}</td><td>{{transformation .}}</td><td>{{.Rows}}</td><td>{{.Nulls}}</td><td>{{.Fallbacks}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+func (r AuditReport) Html() (string, error) {
+	var buffer bytes.Buffer
+	err := htmlReportTemplate.Execute(&buffer, r)
+	return buffer.String(), err
+}
+
+func getTransformationDescription(column ColumnAudit) string {
+	if column.Transformed {
+		return column.Strategy
+	}
+	return "verbatim (" + column.Reason + ")"
+}
+
+func passedToString(passed bool) string {
+	if passed {
+		return "passed"
+	}
+	return "failed"
+}
+
+func escapeMarkdown(value string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(value)
+}
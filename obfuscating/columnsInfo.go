@@ -5,7 +5,6 @@ import (
 	"fmt"
 	_ "github.com/go-sql-driver/mysql"
 	"obfuscator/encoding"
-	"strings"
 )
 
 type RawColumn struct {
@@ -19,6 +18,7 @@ type RawColumn struct {
 
 const (
 	primaryKeyWord = "PRI"
+	uniqueKeyWord  = "UNI"
 )
 
 func getColumnsInfo(db *sql.DB, tableName string) ([]Column, error) {
@@ -26,6 +26,10 @@ func getColumnsInfo(db *sql.DB, tableName string) ([]Column, error) {
 	if err != nil {
 		return nil, err
 	}
+	foreignKeyColumns, err := getForeignKeyColumns(db, tableName)
+	if err != nil {
+		return nil, err
+	}
 	hasPrimaryKey := false
 	var columns []Column
 	for _, rawColumn := range rawColumns {
@@ -33,6 +37,8 @@ func getColumnsInfo(db *sql.DB, tableName string) ([]Column, error) {
 		column.Name = rawColumn.Field
 		column.Type = rawColumn.Type
 		column.NeedToObfuscate = needToObfuscate(rawColumn)
+		column.Unique = rawColumn.Key == uniqueKeyWord && !foreignKeyColumns[rawColumn.Field] &&
+			encoding.IsSupportedType(rawColumn.Type)
 		if rawColumn.Key == primaryKeyWord {
 			column.IsPrimaryKey = true
 			hasPrimaryKey = true
@@ -46,7 +52,7 @@ func getColumnsInfo(db *sql.DB, tableName string) ([]Column, error) {
 }
 
 func showColumns(db *sql.DB, tableName string) ([]RawColumn, error) {
-	rows, err := db.Query(fmt.Sprintf("SHOW COLUMNS FROM %v", tableName))
+	rows, err := db.Query(getShowColumnsQuery(tableName))
 	if err != nil {
 		return nil, err
 	}
@@ -65,17 +71,38 @@ func showColumns(db *sql.DB, tableName string) ([]RawColumn, error) {
 func needToObfuscate(column RawColumn) bool {
 	//can take PRI, UNI, MUL values. We don't obfuscate columns with them not to violate PRIMARY KEY, FOREIGN KEY,
 	//UNIQUE constraints and to save indexes structure
-	//(all indexes in a usual case will be copied due to using SHOW CREATE TABLE statement(tested on BTREE, HASH))
+	//(all indexes in a usual case will be copied due to using SHOW CREATE TABLE statement(tested on BTREE, HASH)).
+	//UNI columns can be selected in request explicitly, see Column.Unique
 	if column.Key != "" {
 		return false
 	}
-	t := column.Type
-	if t == encoding.TinyintType || t == encoding.SmallintType || t == encoding.MediumintType || t == encoding.IntType || t == encoding.BigintType ||
-		t == encoding.UTinyintType || t == encoding.USmallintType || t == encoding.UMediumintType || t == encoding.UIntType || t == encoding.UBigintType ||
-		t == encoding.FloatType || t == encoding.DoubleType || strings.HasPrefix(t, encoding.DecimalType) ||
-		strings.HasPrefix(t, encoding.CharType) || strings.HasPrefix(t, encoding.VarcharType) ||
-		t == encoding.TinytextType || t == encoding.TextType || t == encoding.MediumtextType || t == encoding.LongtextType {
-		return true
+	return encoding.IsSupportedType(column.Type)
+}
+
+//columns of the table referencing other tables or referenced by them
+func getForeignKeyColumns(db *sql.DB, tableName string) (map[string]bool, error) {
+	rows, err := db.Query("SELECT table_name, column_name, referenced_table_name, referenced_column_name"+
+		" FROM information_schema.key_column_usage WHERE table_schema = DATABASE()"+
+		" AND referenced_table_name IS NOT NULL AND (table_name = ? OR referenced_table_name = ?);",
+		tableName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]bool)
+	for rows.Next() {
+		var table, column, referencedTable, referencedColumn string
+		err = rows.Scan(&table, &column, &referencedTable, &referencedColumn)
+		if err != nil {
+			return nil, err
+		}
+		if table == tableName {
+			result[column] = true
+		}
+		if referencedTable == tableName {
+			result[referencedColumn] = true
+		}
 	}
-	return false
+	return result, rows.Err()
 }
@@ -1,6 +1,9 @@
 package obfuscating
 
-import "fmt"
+import (
+	"fmt"
+	"obfuscator/encoding"
+)
 
 func ValidateObfuscationModel(model map[string][]Column, dbConnInfo ConnectionInfo) error {
 	dbInfo, err := GetSchemaInfo(dbConnInfo)
@@ -22,6 +25,15 @@ func ValidateObfuscationModel(model map[string][]Column, dbConnInfo ConnectionIn
 			return fmt.Errorf("count of columns of table %v aren't equal in model and in schema", mTableName)
 		}
 
+		err = validateTemplates(mTableName, mColumnsSlice)
+		if err != nil {
+			return err
+		}
+		err = validateShuffles(mTableName, mColumnsSlice)
+		if err != nil {
+			return err
+		}
+
 		mColumns := castColumnsSliceToMap(mColumnsSlice)
 		dColumns := castColumnsSliceToMap(dColumnsSlice)
 
@@ -37,11 +49,24 @@ func ValidateObfuscationModel(model map[string][]Column, dbConnInfo ConnectionIn
 					" in model in schema ", mColumn.Name, mTableName)
 			}
 
-			if mColumn.NeedToObfuscate && !dColumn.NeedToObfuscate {
+			if mColumn.Unique != dColumn.Unique {
+				return fmt.Errorf("unique values in model and in schema aren't equal."+
+					" Table name: %v, Column name: %v", mTableName, dColumn.Name)
+			}
+
+			//UNI columns are obfuscated only if they're selected explicitly
+			if mColumn.NeedToObfuscate && !dColumn.NeedToObfuscate && !dColumn.Unique {
 				return fmt.Errorf("you cann't obfuscate this column."+
 					" Table name: %v, Column name: %v, Type: %v", mTableName, dColumn.Name, dColumn.Type)
 			}
 
+			if mColumn.NeedToObfuscate {
+				err = encoding.ValidateStrategy(mColumn.Strategy, dColumn.Type, mColumn.Params)
+				if err != nil {
+					return fmt.Errorf("%v. Table name: %v, Column name: %v", err, mTableName, dColumn.Name)
+				}
+			}
+
 			if mColumn.IsPrimaryKey != dColumn.IsPrimaryKey {
 				return fmt.Errorf("isPrimaryKey values in model and in schema aren't equal."+
 					" Table name: %v, Column name: %v", mTableName, dColumn.Name)
@@ -52,6 +77,23 @@ func ValidateObfuscationModel(model map[string][]Column, dbConnInfo ConnectionIn
 	return nil
 }
 
+func ValidateConnectionInfo(connInfo ConnectionInfo) error {
+	if connInfo.User == "" || (connInfo.Host == "" && connInfo.Socket == "") || connInfo.Schema == "" {
+		return fmt.Errorf("either Profile or User, Host (or Socket) and Schema are required")
+	}
+	_, err := getDsn(connInfo)
+	return err
+}
+
+//schema isn't required, e.g. for profiles used with several schemas
+func ValidateServerInfo(connInfo ConnectionInfo) error {
+	if connInfo.User == "" || (connInfo.Host == "" && connInfo.Socket == "") {
+		return fmt.Errorf("User and Host (or Socket) are required")
+	}
+	_, err := getDsn(connInfo)
+	return err
+}
+
 func GetSchemaInfo(dbConnInfo ConnectionInfo) (map[string][]Column, error) {
 	db, err := openDbConnection(dbConnInfo)
 	if err != nil {
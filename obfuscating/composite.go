@@ -0,0 +1,130 @@
+package obfuscating
+
+import (
+	"fmt"
+	"obfuscator/encoding"
+)
+
+//renders template columns from values of other columns of the row after they're obfuscated,
+//values are in model order, original value is kept if rendering was failed
+//or if any referenced column fell back to its original value
+func renderTemplates(row map[string]*interface{}, model []Column, values []interface{}, unique uniqueValues,
+	errs map[string]error) {
+	obfuscatedRow := make(map[string]interface{}, len(model))
+	for i, column := range model {
+		obfuscatedRow[column.Name] = values[i]
+	}
+	for i, column := range model {
+		if !column.NeedToObfuscate || column.Strategy != encoding.TemplateStrategy {
+			continue
+		}
+		//NULL stays NULL as with other strategies
+		if isNull(row[column.Name]) {
+			continue
+		}
+		references, err := encoding.GetTemplateColumns(column.Params)
+		if err != nil {
+			errs[column.Name] = err
+			continue
+		}
+		if reference := getFailedReference(references, errs); reference != "" {
+			errs[column.Name] = fmt.Errorf("column %v referenced by template wasn't obfuscated", reference)
+			continue
+		}
+		generate := func() (interface{}, error) {
+			return encoding.RenderTemplate(column.Type, column.Params, obfuscatedRow)
+		}
+		value, err := generate()
+		if err == nil {
+			value, err = unique.add(column, value, generate)
+		}
+		if err != nil {
+			errs[column.Name] = err
+			continue
+		}
+		values[i] = value
+	}
+}
+
+//fills tuple columns of each reference set from one row of the set chosen by original values of these columns,
+//so templates can refer to them. Original value is kept if the value wasn't found
+func fillTuples(row map[string]*interface{}, model []Column, values []interface{}, unique uniqueValues,
+	errs map[string]error) {
+	//reference set -> original values of its columns in model order
+	originals := make(map[string][]interface{})
+	for _, column := range model {
+		if column.NeedToObfuscate && column.Strategy == encoding.TupleStrategy {
+			set := column.Params[encoding.TupleSetParam]
+			originals[set] = append(originals[set], row[column.Name])
+		}
+	}
+	for i, column := range model {
+		if !column.NeedToObfuscate || column.Strategy != encoding.TupleStrategy {
+			continue
+		}
+		//NULL stays NULL as with other strategies
+		if isNull(row[column.Name]) {
+			continue
+		}
+		generate := func() (interface{}, error) {
+			return encoding.TupleValue(column.Type, column.Params, originals[column.Params[encoding.TupleSetParam]])
+		}
+		value, err := generate()
+		if err == nil {
+			value, err = unique.add(column, value, generate)
+		}
+		if err != nil {
+			errs[column.Name] = err
+			continue
+		}
+		values[i] = value
+	}
+}
+
+func getFailedReference(references []string, errs map[string]error) string {
+	for _, reference := range references {
+		if _, failed := errs[reference]; failed {
+			return reference
+		}
+	}
+	return ""
+}
+
+//template columns can refer only to other obfuscated columns of the table which aren't rendered by templates,
+//otherwise original values would be copied to template columns
+func validateTemplates(tableName string, model []Column) error {
+	templateColumns := make(map[string]bool)
+	obfuscatedColumns := make(map[string]bool)
+	for _, column := range model {
+		if column.NeedToObfuscate && column.Strategy == encoding.TemplateStrategy {
+			templateColumns[column.Name] = true
+		}
+		if column.NeedToObfuscate && column.Strategy != encoding.KeepStrategy {
+			obfuscatedColumns[column.Name] = true
+		}
+	}
+	for _, column := range model {
+		if !templateColumns[column.Name] {
+			continue
+		}
+		references, err := encoding.GetTemplateColumns(column.Params)
+		if err != nil {
+			return fmt.Errorf("invalid template of column %v in table %v: %v", column.Name, tableName, err)
+		}
+		for _, reference := range references {
+			if !hasColumn(model, reference) {
+				return fmt.Errorf("template of column %v refers to column %v which table %v hasn't",
+					column.Name, reference, tableName)
+			}
+			if templateColumns[reference] {
+				return fmt.Errorf("template of column %v refers to template column %v in table %v",
+					column.Name, reference, tableName)
+			}
+			if !obfuscatedColumns[reference] {
+				return fmt.Errorf("template of column %v refers to column %v in table %v which isn't obfuscated",
+					column.Name, reference, tableName)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package obfuscating
+
+import (
+	"fmt"
+	"obfuscator/encoding"
+	"testing"
+)
+
+func templateModel(template string) []Column {
+	return []Column{
+		{Name: "id", Type: encoding.IntType, IsPrimaryKey: true},
+		{Name: "first_name", Type: "varchar(20)", NeedToObfuscate: true, Strategy: encoding.LoremStrategy},
+		{Name: "last_name", Type: "varchar(20)", NeedToObfuscate: true, Strategy: encoding.KeepStrategy},
+		{Name: "login", Type: "varchar(20)"},
+		{Name: "email", Type: "varchar(40)", NeedToObfuscate: true, Strategy: encoding.TemplateStrategy,
+			Params: map[string]string{encoding.TemplateParam: template}},
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	tests := []struct {
+		template string
+		valid    bool
+	}{
+		{"{first_name|lower}@example.com", true},
+		{"{first_name}.{last_name}@example.com", false},
+		{"{login}@example.com", false},
+		{"{id}@example.com", false},
+		{"{email}", false},
+		{"{phone}", false},
+		{"{first_name", false},
+	}
+	for _, test := range tests {
+		err := validateTemplates("users", templateModel(test.template))
+		if (err == nil) != test.valid {
+			t.Errorf("%v: unexpected result %v", test.template, err)
+		}
+	}
+}
+
+func TestRenderTemplates(t *testing.T) {
+	model := templateModel("{first_name|lower}@example.com")
+	var id, first, last, login, email interface{} = 1, "John", "Smith", "jsmith", "john@mail.com"
+	row := map[string]*interface{}{"id": &id, "first_name": &first, "last_name": &last, "login": &login,
+		"email": &email}
+	values := []interface{}{&id, "Lorem", &last, &login, &email}
+
+	errs := make(map[string]error)
+	renderTemplates(row, model, values, getUniqueValues(model), errs)
+	if len(errs) != 0 || values[4] != "lorem@example.com" {
+		t.Errorf("unexpected rendered value %v, %v", values[4], errs)
+	}
+
+	//original value of referenced column mustn't be rendered into template column
+	values = []interface{}{&id, &first, &last, &login, &email}
+	errs = map[string]error{"first_name": fmt.Errorf("can't obfuscate")}
+	renderTemplates(row, model, values, getUniqueValues(model), errs)
+	if errs["email"] == nil || values[4] != &email {
+		t.Errorf("template of failed column must fall back, got %v, %v", values[4], errs)
+	}
+}
+
+func TestFillTuples(t *testing.T) {
+	tuple := func(name, field string) Column {
+		return Column{Name: name, Type: "varchar(40)", NeedToObfuscate: true, Strategy: encoding.TupleStrategy,
+			Params: map[string]string{encoding.TupleSetParam: encoding.AddressReferenceSet,
+				encoding.TupleFieldParam: field}}
+	}
+	model := []Column{
+		{Name: "id", Type: encoding.IntType, IsPrimaryKey: true},
+		tuple("city", "city"),
+		tuple("zip", "postcode"),
+		tuple("country", "country"),
+		tuple("region", "country"),
+		{Name: "address", Type: "varchar(80)", NeedToObfuscate: true, Strategy: encoding.TemplateStrategy,
+			Params: map[string]string{encoding.TemplateParam: "{zip} {city}, {country}"}},
+	}
+	if err := validateTemplates("users", model); err != nil {
+		t.Fatal(err)
+	}
+	var id, city, zip, country, address interface{} = 1, "Springfield", "12345", "USA", "12345 Springfield, USA"
+	row := map[string]*interface{}{"id": &id, "city": &city, "zip": &zip, "country": &country, "region": nil,
+		"address": &address}
+	values, errs := obfuscateRow(row, model, getUniqueValues(model))
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	if !isNull(values[4]) {
+		t.Errorf("NULL must stay NULL, got %v", values[4])
+	}
+	expected := fmt.Sprintf("%v %v, %v", values[2], values[1], values[3])
+	if values[5] != expected || values[1] == city {
+		t.Errorf("unexpected values %v", values)
+	}
+	again, _ := obfuscateRow(row, model, getUniqueValues(model))
+	if fmt.Sprint(again) != fmt.Sprint(values) {
+		t.Errorf("the same tuple must get the same values, got %v and %v", values, again)
+	}
+}
@@ -0,0 +1,128 @@
+package obfuscating
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+//modes of handling tables which already exist in destination
+const (
+	FailMode     = "fail" //default
+	DropMode     = "drop" //drop and recreate
+	TruncateMode = "truncate"
+	AppendMode   = "append"
+	UpsertMode   = "upsert" //INSERT ... ON DUPLICATE KEY UPDATE
+)
+
+func ValidateConflictModes(request ObfuscateRequest) error {
+	if !isKnownConflictMode(request.ConflictMode) {
+		return fmt.Errorf("unknown conflict mode %v", request.ConflictMode)
+	}
+	for table, mode := range request.TableConflictModes {
+		if _, contains := request.Model[table]; !contains {
+			return fmt.Errorf("conflict mode is set for table %v which model hasn't", table)
+		}
+		if !isKnownConflictMode(mode) {
+			return fmt.Errorf("unknown conflict mode %v for table %v", mode, table)
+		}
+	}
+	return nil
+}
+
+func (request ObfuscateRequest) getConflictMode(table string) string {
+	if mode, contains := request.TableConflictModes[table]; contains && mode != "" {
+		return mode
+	}
+	if request.ConflictMode == "" {
+		return FailMode
+	}
+	return request.ConflictMode
+}
+
+func isKnownConflictMode(mode string) bool {
+	switch mode {
+	case "", FailMode, DropMode, TruncateMode, AppendMode, UpsertMode:
+		return true
+	}
+	return false
+}
+
+//creates table in destination or prepares existing one according to conflict mode
+func prepareDestinationTable(originalDb, destinationDb *sql.DB, tableName, schemaName, mode string) error {
+	exists, err := tableExists(destinationDb, schemaName, tableName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return createTableCopy(originalDb, destinationDb, tableName)
+	}
+
+	switch mode {
+	case DropMode:
+		err = execWithoutForeignKeyChecks(destinationDb, fmt.Sprintf("DROP TABLE %v;", quoteIdentifier(tableName)))
+		if err != nil {
+			return err
+		}
+		return createTableCopy(originalDb, destinationDb, tableName)
+	case TruncateMode:
+		return execWithoutForeignKeyChecks(destinationDb, fmt.Sprintf("TRUNCATE TABLE %v;", quoteIdentifier(tableName)))
+	case AppendMode, UpsertMode:
+		return nil
+	default:
+		return fmt.Errorf("table %v already exists in destination", tableName)
+	}
+}
+
+func tableExists(db *sql.DB, schemaName, tableName string) (bool, error) {
+	tables, err := getTables(db, schemaName)
+	if err != nil {
+		return false, err
+	}
+	for _, table := range tables {
+		if table == tableName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//referencing tables in destination would block dropping and truncating otherwise,
+//FOREIGN_KEY_CHECKS is a session variable so single connection is used
+func execWithoutForeignKeyChecks(db *sql.DB, query string) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0;")
+	if err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1;")
+
+	_, err = conn.ExecContext(ctx, query)
+	return err
+}
+
+//updates all columns except primary key ones
+func getUpsertClause(columns []Column) string {
+	var updates []string
+	var primaryKeyColumn string
+	for _, column := range columns {
+		name := quoteIdentifier(column.Name)
+		if !column.IsPrimaryKey {
+			updates = append(updates, fmt.Sprintf("%v = VALUES(%v)", name, name))
+		} else if primaryKeyColumn == "" {
+			primaryKeyColumn = name
+		}
+	}
+	if len(updates) == 0 {
+		//nothing to update, duplicates are just skipped
+		updates = append(updates, fmt.Sprintf("%v = %v", primaryKeyColumn, primaryKeyColumn))
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+}
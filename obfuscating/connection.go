@@ -0,0 +1,221 @@
+package obfuscating
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"net/url"
+	"obfuscator/config"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+//TLS modes
+const (
+	TlsDisabled   = "disabled" //default
+	TlsPreferred  = "preferred"
+	TlsSkipVerify = "skip-verify"
+	TlsVerifyCa   = "verify-ca" //certificate is verified, host name isn't
+	TlsVerifyFull = "verify-full"
+)
+
+//extra DSN parameters which can't weaken TLS and authentication, enable LOCAL INFILE or multiple statements,
+//or change how values are read and written
+var allowedDsnParams = map[string]bool{
+	"maxAllowedPacket":     true,
+	"checkConnLiveness":    true,
+	"rejectReadOnly":       true,
+	"connectionAttributes": true,
+	//session variables
+	"wait_timeout":       true,
+	"net_read_timeout":   true,
+	"net_write_timeout":  true,
+	"max_execution_time": true,
+	"lock_wait_timeout":  true,
+}
+
+const (
+	defaultCharset = "utf8mb4"
+	tcpNet         = "tcp"
+	unixNet        = "unix"
+)
+
+func openDbConnection(connInfo ConnectionInfo) (*sql.DB, error) {
+	dsn, err := getDsn(connInfo)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(MysqlDriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(config.GetConfig().Db.MaxOpenConnections)
+	return db, nil
+}
+
+func getDsn(connInfo ConnectionInfo) (string, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = connInfo.User
+	cfg.Passwd = connInfo.Password
+	cfg.DBName = connInfo.Schema
+	cfg.InterpolateParams = true
+	if connInfo.Socket != "" {
+		cfg.Net = unixNet
+		cfg.Addr = connInfo.Socket
+	} else {
+		cfg.Net = tcpNet
+		cfg.Addr = connInfo.Host
+	}
+
+	charset := connInfo.Charset
+	if charset == "" {
+		charset = defaultCharset
+	}
+	cfg.Params = map[string]string{"charset": charset}
+	cfg.Collation = connInfo.Collation
+
+	var err error
+	cfg.Timeout, err = parseTimeout(connInfo.ConnectTimeout, "ConnectTimeout")
+	if err != nil {
+		return "", err
+	}
+	cfg.ReadTimeout, err = parseTimeout(connInfo.ReadTimeout, "ReadTimeout")
+	if err != nil {
+		return "", err
+	}
+	cfg.WriteTimeout, err = parseTimeout(connInfo.WriteTimeout, "WriteTimeout")
+	if err != nil {
+		return "", err
+	}
+
+	cfg.TLSConfig, err = registerTlsConfig(connInfo)
+	if err != nil {
+		return "", err
+	}
+
+	dsn := cfg.FormatDSN()
+	//allowed parameters don't overlap with the ones set above
+	var keys []string
+	for key := range connInfo.Params {
+		if !allowedDsnParams[key] {
+			return "", fmt.Errorf("DSN parameter %v isn't allowed", key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		separator := "&"
+		if !strings.Contains(dsn, "?") {
+			separator = "?"
+		}
+		dsn += separator + url.QueryEscape(key) + "=" + url.QueryEscape(connInfo.Params[key])
+	}
+	return dsn, nil
+}
+
+func parseTimeout(value, name string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %v: %v", name, err)
+	}
+	return timeout, nil
+}
+
+//returns name of TLS config for DSN
+func registerTlsConfig(connInfo ConnectionInfo) (string, error) {
+	switch connInfo.TlsMode {
+	case "", TlsDisabled:
+		return "false", nil
+	case TlsPreferred:
+		return "preferred", nil
+	case TlsSkipVerify:
+		if connInfo.TlsCert == "" {
+			return "skip-verify", nil
+		}
+	case TlsVerifyCa, TlsVerifyFull:
+	default:
+		return "", fmt.Errorf("unknown TLS mode %v", connInfo.TlsMode)
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: connInfo.TlsServerName,
+	}
+	if tlsConfig.ServerName == "" && connInfo.Socket == "" {
+		tlsConfig.ServerName = strings.Split(connInfo.Host, ":")[0]
+	}
+
+	if connInfo.TlsCa != "" {
+		pem, err := os.ReadFile(connInfo.TlsCa)
+		if err != nil {
+			return "", err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return "", fmt.Errorf("can't parse CA certificate %v", connInfo.TlsCa)
+		}
+	}
+	if connInfo.TlsCert != "" || connInfo.TlsKey != "" {
+		certificate, err := tls.LoadX509KeyPair(connInfo.TlsCert, connInfo.TlsKey)
+		if err != nil {
+			return "", err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	switch connInfo.TlsMode {
+	case TlsSkipVerify:
+		tlsConfig.InsecureSkipVerify = true
+	case TlsVerifyCa:
+		//chain is verified manually without host name
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = getCaVerifier(tlsConfig.RootCAs)
+	}
+
+	name := getTlsConfigName(connInfo)
+	err := mysql.RegisterTLSConfig(name, tlsConfig)
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func getCaVerifier(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("server hasn't sent certificate")
+		}
+		var certificates []*x509.Certificate
+		for _, rawCert := range rawCerts {
+			certificate, err := x509.ParseCertificate(rawCert)
+			if err != nil {
+				return err
+			}
+			certificates = append(certificates, certificate)
+		}
+		intermediates := x509.NewCertPool()
+		for _, certificate := range certificates[1:] {
+			intermediates.AddCert(certificate)
+		}
+		_, err := certificates[0].Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+		return err
+	}
+}
+
+//configs are registered globally, so the name depends on all TLS settings
+func getTlsConfigName(connInfo ConnectionInfo) string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{connInfo.TlsMode, connInfo.TlsCa, connInfo.TlsCert,
+		connInfo.TlsKey, connInfo.TlsServerName, connInfo.Host, connInfo.Socket}, "\x00")))
+	return "obfuscator-" + hex.EncodeToString(hash[:8])
+}
@@ -0,0 +1,39 @@
+package obfuscating
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetDsnParams(t *testing.T) {
+	tests := []struct {
+		params   map[string]string
+		valid    bool
+		expected string
+	}{
+		{nil, true, "tls=false&charset=utf8mb4"},
+		{map[string]string{"maxAllowedPacket": "0", "wait_timeout": "60"}, true,
+			"&maxAllowedPacket=0&wait_timeout=60"},
+		{map[string]string{"allowAllFiles": "true"}, false, ""},
+		{map[string]string{"allowCleartextPasswords": "true"}, false, ""},
+		{map[string]string{"allowFallbackToPlaintext": "true"}, false, ""},
+		{map[string]string{"tls": "false"}, false, ""},
+		{map[string]string{"serverPubKey": "key"}, false, ""},
+		{map[string]string{"multiStatements": "true"}, false, ""},
+		{map[string]string{"parseTime": "true"}, false, ""},
+		{map[string]string{"charset": "latin1"}, false, ""},
+		{map[string]string{"sql_mode": "''"}, false, ""},
+	}
+	for _, test := range tests {
+		connInfo := ConnectionInfo{User: "user", Password: "secret", Host: "localhost:3306", Schema: "users",
+			TlsMode: TlsDisabled, Params: test.params}
+		dsn, err := getDsn(connInfo)
+		if (err == nil) != test.valid {
+			t.Errorf("%v: unexpected result %v", test.params, err)
+			continue
+		}
+		if err == nil && !strings.HasSuffix(dsn, test.expected) {
+			t.Errorf("%v: unexpected DSN %v", test.params, dsn)
+		}
+	}
+}
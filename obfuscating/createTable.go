@@ -25,7 +25,7 @@ func createTableCopy(originalDb, destinationDb *sql.DB, tableName string) error
 }
 
 func showCreateTable(db *sql.DB, tableName string) (*string, error) {
-	rows, err := db.Query(fmt.Sprintf("SHOW CREATE TABLE %v;", tableName))
+	rows, err := db.Query(getShowCreateTableQuery(tableName))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,109 @@
+package obfuscating
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"obfuscator/encoding"
+	"strconv"
+)
+
+//returns copy of the model where columns with preserved distribution get weights or histogram of origin values,
+//the ones set in the model are kept
+func fillDistributions(db *sql.DB, tableName string, model []Column) ([]Column, error) {
+	result := make([]Column, len(model))
+	copy(result, model)
+	for i, column := range result {
+		if !column.NeedToObfuscate {
+			continue
+		}
+		var param, value string
+		var err error
+		switch {
+		case column.Strategy == encoding.MemberStrategy &&
+			column.Params[encoding.DistributionParam] == encoding.PreserveDistribution &&
+			column.Params[encoding.WeightsParam] == "":
+			param = encoding.WeightsParam
+			value, err = getValueWeights(db, tableName, column.Name)
+		case column.Strategy == encoding.QuantileStrategy && column.Params[encoding.HistogramParam] == "":
+			param = encoding.HistogramParam
+			value, err = getHistogram(db, tableName, column)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		params := make(map[string]string)
+		for key, value := range column.Params {
+			params[key] = value
+		}
+		params[param] = value
+		result[i].Params = params
+	}
+	return result, nil
+}
+
+func getValueWeights(db *sql.DB, tableName, columnName string) (string, error) {
+	column := quoteIdentifier(columnName)
+	rows, err := db.Query(fmt.Sprintf("SELECT %v, COUNT(*) FROM %v WHERE %v IS NOT NULL GROUP BY %v",
+		column, quoteIdentifier(tableName), column, column))
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	weights := make(map[string]int64)
+	for rows.Next() {
+		var value string
+		var count int64
+		err = rows.Scan(&value, &count)
+		if err != nil {
+			return "", err
+		}
+		weights[value] = count
+	}
+	if err = rows.Err(); err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(weights)
+	return string(encoded), err
+}
+
+//edges of equal-frequency buckets: minimums of buckets and maximum of the last one
+func getHistogram(db *sql.DB, tableName string, column Column) (string, error) {
+	buckets := encoding.DefaultBuckets
+	if value := column.Params[encoding.BucketsParam]; value != "" {
+		var err error
+		buckets, err = strconv.Atoi(value)
+		if err != nil || buckets <= 0 {
+			return "", fmt.Errorf("param %v must be positive integer", encoding.BucketsParam)
+		}
+	}
+	name := quoteIdentifier(column.Name)
+	rows, err := db.Query(fmt.Sprintf("SELECT MIN(%v), MAX(%v) FROM"+
+		" (SELECT %v, NTILE(?) OVER (ORDER BY %v) AS bucket FROM %v WHERE %v IS NOT NULL) AS buckets"+
+		" GROUP BY bucket ORDER BY bucket", name, name, name, name, quoteIdentifier(tableName), name), buckets)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	var edges []float64
+	var last float64
+	for rows.Next() {
+		var minValue, maxValue float64
+		err = rows.Scan(&minValue, &maxValue)
+		if err != nil {
+			return "", err
+		}
+		edges = append(edges, minValue)
+		last = maxValue
+	}
+	if err = rows.Err(); err != nil {
+		return "", err
+	}
+	if len(edges) > 0 {
+		edges = append(edges, last)
+	}
+	encoded, err := json.Marshal(edges)
+	return string(encoded), err
+}
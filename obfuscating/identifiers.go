@@ -0,0 +1,44 @@
+package obfuscating
+
+import (
+	"fmt"
+	"strings"
+)
+
+//quotes table, column or schema name with backticks, backticks inside are doubled
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func quoteIdentifiers(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteIdentifier(name)
+	}
+	return quoted
+}
+
+func getSelectSliceQuery(tableName string, model []Column, limit, offset int) string {
+	return fmt.Sprintf(selectBySlicesQuery, quoteIdentifier(tableName), getOrderByValues(model), limit, offset)
+}
+
+func getLockTableQuery(tableName string) string {
+	return fmt.Sprintf("LOCK TABLES %v READ;", quoteIdentifier(tableName))
+}
+
+func getShowColumnsQuery(tableName string) string {
+	return "SHOW COLUMNS FROM " + quoteIdentifier(tableName)
+}
+
+func getShowCreateTableQuery(tableName string) string {
+	return fmt.Sprintf("SHOW CREATE TABLE %v;", quoteIdentifier(tableName))
+}
+
+func getInsertQuery(tableName string, model []Column, rowsCount int, mode string) string {
+	valuesTemplate, columnNames := getInsertsTemplate(model, rowsCount)
+	insertQuery := "INSERT INTO " + quoteIdentifier(tableName) + " (" + columnNames + ") VALUES " + valuesTemplate
+	if mode == UpsertMode {
+		insertQuery += getUpsertClause(model)
+	}
+	return insertQuery + ";"
+}
@@ -0,0 +1,195 @@
+package obfuscating
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"users", "`users`"},
+		{"order", "`order`"},
+		{"select", "`select`"},
+		{"first name", "`first name`"},
+		{"a`b", "`a``b`"},
+		{"`", "````"},
+		{"``x``", "`````x`````"},
+		{"name; DROP TABLE users; --", "`name; DROP TABLE users; --`"},
+		{"пользователи", "`пользователи`"},
+		{"名前", "`名前`"},
+		{"", "``"},
+	}
+	for _, test := range tests {
+		actual := quoteIdentifier(test.name)
+		if actual != test.expected {
+			t.Errorf("quoteIdentifier(%q) = %q, expected %q", test.name, actual, test.expected)
+		}
+	}
+}
+
+func TestQuoteIdentifiers(t *testing.T) {
+	actual := quoteIdentifiers([]string{"order", "a`b"})
+	if len(actual) != 2 || actual[0] != "`order`" || actual[1] != "`a``b`" {
+		t.Errorf("unexpected result %q", actual)
+	}
+}
+
+func TestQueries(t *testing.T) {
+	model := []Column{
+		{Name: "order", Type: "int", IsPrimaryKey: true},
+		{Name: "first name", Type: "varchar(10)", NeedToObfuscate: true},
+		{Name: "a`b", Type: "text"},
+	}
+	tests := []struct {
+		name     string
+		actual   string
+		expected string
+	}{
+		{"select", getSelectSliceQuery("group", model, 20, 40),
+			"SELECT * FROM `group` ORDER BY `order` LIMIT 20 OFFSET 40;"},
+		{"lock", getLockTableQuery("user`s"), "LOCK TABLES `user``s` READ;"},
+		{"show columns", getShowColumnsQuery("my table"), "SHOW COLUMNS FROM `my table`"},
+		{"show create table", getShowCreateTableQuery("таблица"), "SHOW CREATE TABLE `таблица`;"},
+		{"insert", getInsertQuery("order", model, 2, FailMode),
+			"INSERT INTO `order` (`order`,`first name`,`a``b`) VALUES (?,?,?),(?,?,?);"},
+		{"upsert", getInsertQuery("order", model, 1, UpsertMode),
+			"INSERT INTO `order` (`order`,`first name`,`a``b`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE " +
+				"`first name` = VALUES(`first name`), `a``b` = VALUES(`a``b`);"},
+		{"upsert of primary key only", getInsertQuery("key", model[:1], 1, UpsertMode),
+			"INSERT INTO `key` (`order`) VALUES (?) ON DUPLICATE KEY UPDATE `order` = `order`;"},
+	}
+	for _, test := range tests {
+		if test.actual != test.expected {
+			t.Errorf("%v: got %q, expected %q", test.name, test.actual, test.expected)
+		}
+	}
+}
+
+func TestMetadataQueriesBindNames(t *testing.T) {
+	db, recorder := openRecordingDb(t)
+	schema := "sch`ema'; --"
+	table := "ta`ble' OR '1'='1"
+
+	_, _ = getTables(db, schema)
+	_, _ = getDependencies(db, schema, table)
+	_, _ = getTableStatuses(db, schema)
+	_, _ = getForeignKeys(db, schema)
+	_, _ = getForeignKeyColumns(db, table)
+
+	queries := recorder.get()
+	if len(queries) != 5 {
+		t.Fatalf("expected 5 queries, got %v", len(queries))
+	}
+	for _, query := range queries {
+		if strings.Contains(query.text, "sch`ema") || strings.Contains(query.text, "ta`ble") {
+			t.Errorf("name is spliced into query %q", query.text)
+		}
+		if strings.Count(query.text, "?") != len(query.args) {
+			t.Errorf("query %q has %v args", query.text, len(query.args))
+		}
+		for _, arg := range query.args {
+			if arg != schema && arg != table {
+				t.Errorf("unexpected arg %v of query %q", arg, query.text)
+			}
+		}
+	}
+}
+
+type recordedQuery struct {
+	text string
+	args []interface{}
+}
+
+//records queries and returns no rows, so metadata functions can be checked without database
+type queryRecorder struct {
+	mutex   sync.Mutex
+	queries []recordedQuery
+}
+
+func (r *queryRecorder) get() []recordedQuery {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]recordedQuery(nil), r.queries...)
+}
+
+var recordersCount int
+
+func openRecordingDb(t *testing.T) (*sql.DB, *queryRecorder) {
+	recorder := &queryRecorder{}
+	recordersCount++
+	name := fmt.Sprintf("recorder%v", recordersCount)
+	sql.Register(name, recordingDriver{recorder})
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, recorder
+}
+
+type recordingDriver struct {
+	recorder *queryRecorder
+}
+
+func (d recordingDriver) Open(_ string) (driver.Conn, error) {
+	return recordingConn{d.recorder}, nil
+}
+
+type recordingConn struct {
+	recorder *queryRecorder
+}
+
+func (c recordingConn) Prepare(_ string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepared statements aren't supported")
+}
+
+func (c recordingConn) Close() error {
+	return nil
+}
+
+func (c recordingConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions aren't supported")
+}
+
+func (c recordingConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.record(query, args)
+	return emptyRows{}, nil
+}
+
+func (c recordingConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+func (c recordingConn) record(query string, args []driver.NamedValue) {
+	recorded := recordedQuery{text: query}
+	for _, arg := range args {
+		recorded.args = append(recorded.args, arg.Value)
+	}
+	c.recorder.mutex.Lock()
+	defer c.recorder.mutex.Unlock()
+	c.recorder.queries = append(c.recorder.queries, recorded)
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string {
+	return []string{"value"}
+}
+
+func (emptyRows) Close() error {
+	return nil
+}
+
+func (emptyRows) Next(_ []driver.Value) error {
+	return io.EOF
+}
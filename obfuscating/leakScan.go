@@ -0,0 +1,175 @@
+package obfuscating
+
+import (
+	"database/sql"
+	"fmt"
+	"obfuscator/config"
+	"obfuscator/encoding"
+	"sort"
+)
+
+type LeakReport struct {
+	Passed  bool
+	Columns []ColumnLeaks
+	Errors  []string `json:",omitempty"`
+}
+
+type ColumnLeaks struct {
+	Table         string
+	Column        string
+	ScannedValues int
+	//count of destination rows containing original values of the column
+	LeakedInColumn int64
+	//"table.column" -> count of destination rows, only text columns are scanned
+	LeakedElsewhere map[string]int64 `json:",omitempty"`
+}
+
+//values of these strategies are taken from the domain of original values, e.g. other ENUM members or
+//histogram edges, or from public reference sets like cities, so they are expected in destination
+var reusingValuesStrategies = map[string]bool{
+	encoding.ShuffleStrategy:  true,
+	encoding.TupleStrategy:    true,
+	encoding.MemberStrategy:   true,
+	encoding.BitStrategy:      true,
+	encoding.YearStrategy:     true,
+	encoding.QuantileStrategy: true,
+	encoding.RoundStrategy:    true,
+}
+
+type columnRef struct {
+	table  string
+	column string
+}
+
+//searches original values of obfuscated columns in destination by MD5 hashes,
+//values are sampled if sample size is set in config and fully hashed otherwise
+func ScanLeaks(model map[string][]Column, originalDb, destinationDb *sql.DB) LeakReport {
+	report := LeakReport{Passed: true}
+	sampleSize := config.GetConfig().LeakScan.SampleSize
+	minLength := config.GetConfig().LeakScan.MinValueLength
+
+	//hash -> columns containing the value in origin
+	originalHashes := make(map[string][]columnRef)
+	leaks := make(map[columnRef]*ColumnLeaks)
+	var scannedColumns []columnRef
+	for _, table := range getSortedKeys(model) {
+		for _, column := range model[table] {
+			if !column.NeedToObfuscate || reusesOriginalValues(column) {
+				continue
+			}
+			ref := columnRef{table, column.Name}
+			hashes, err := getValueHashes(originalDb, table, column.Name, sampleSize)
+			if err != nil {
+				report.Passed = false
+				report.Errors = append(report.Errors, fmt.Sprintf("origin %v.%v: %v", table, column.Name, err))
+				continue
+			}
+			for _, hash := range hashes {
+				originalHashes[hash] = append(originalHashes[hash], ref)
+			}
+			leaks[ref] = &ColumnLeaks{
+				Table:           table,
+				Column:          column.Name,
+				ScannedValues:   len(hashes),
+				LeakedElsewhere: make(map[string]int64),
+			}
+			scannedColumns = append(scannedColumns, ref)
+		}
+	}
+
+	for _, table := range getSortedKeys(model) {
+		for _, column := range model[table] {
+			destinationRef := columnRef{table, column.Name}
+			_, isObfuscated := leaks[destinationRef]
+			if !isObfuscated && !encoding.IsStringType(column.Type) {
+				continue
+			}
+			err := scanDestinationColumn(destinationDb, destinationRef, minLength, originalHashes, leaks)
+			if err != nil {
+				report.Passed = false
+				report.Errors = append(report.Errors, fmt.Sprintf("destination %v.%v: %v", table, column.Name, err))
+			}
+		}
+	}
+
+	for _, ref := range scannedColumns {
+		columnLeaks := leaks[ref]
+		if columnLeaks.LeakedInColumn > 0 || len(columnLeaks.LeakedElsewhere) > 0 {
+			report.Passed = false
+		}
+		report.Columns = append(report.Columns, *columnLeaks)
+	}
+	return report
+}
+
+//default strategy of discrete types picks other values of the same small domain
+func reusesOriginalValues(column Column) bool {
+	return reusingValuesStrategies[column.Strategy] ||
+		column.Strategy == encoding.DefaultStrategy && encoding.IsDiscreteType(column.Type)
+}
+
+func getValueHashes(db *sql.DB, table, column string, limit int) ([]string, error) {
+	quotedColumn := quoteIdentifier(column)
+	query := fmt.Sprintf("SELECT DISTINCT MD5(%v) FROM %v WHERE %v IS NOT NULL",
+		quotedColumn, quoteIdentifier(table), quotedColumn)
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %v", limit)
+	}
+	return queryStrings(db, query)
+}
+
+//matches in the same column are counted for any value, in other columns only for long enough values
+//not to count short values like "1" or "yes" as leaks
+func scanDestinationColumn(db *sql.DB, ref columnRef, minLength int, originalHashes map[string][]columnRef,
+	leaks map[columnRef]*ColumnLeaks) error {
+	column := quoteIdentifier(ref.column)
+	rows, err := db.Query(fmt.Sprintf("SELECT MD5(%v), CHAR_LENGTH(%v) FROM %v WHERE %v IS NOT NULL",
+		column, column, quoteIdentifier(ref.table), column))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var hash string
+		var length int
+		err = rows.Scan(&hash, &length)
+		if err != nil {
+			return err
+		}
+		for _, originalRef := range originalHashes[hash] {
+			if originalRef == ref {
+				leaks[originalRef].LeakedInColumn++
+			} else if length >= minLength {
+				leaks[originalRef].LeakedElsewhere[ref.table+"."+ref.column]++
+			}
+		}
+	}
+	return rows.Err()
+}
+
+func queryStrings(db *sql.DB, query string) ([]string, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []string
+	for rows.Next() {
+		var value string
+		err = rows.Scan(&value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+	return result, rows.Err()
+}
+
+func getSortedKeys(model map[string][]Column) []string {
+	var keys []string
+	for key := range model {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,48 @@
+package obfuscating
+
+import (
+	"obfuscator/encoding"
+	"testing"
+)
+
+func TestReusesOriginalValues(t *testing.T) {
+	tests := []struct {
+		column   Column
+		expected bool
+	}{
+		{Column{Type: "enum('a','b')"}, true},
+		{Column{Type: "set('a','b')", Strategy: encoding.MemberStrategy}, true},
+		{Column{Type: "bit(1)"}, true},
+		{Column{Type: "tinyint(1)", Strategy: encoding.BitStrategy}, true},
+		{Column{Type: "year"}, true},
+		{Column{Type: encoding.IntType, Strategy: encoding.QuantileStrategy}, true},
+		{Column{Type: encoding.IntType, Strategy: encoding.RoundStrategy}, true},
+		{Column{Type: "varchar(20)", Strategy: encoding.ShuffleStrategy}, true},
+		{Column{Type: "enum('a','b')", Strategy: encoding.NullStrategy}, false},
+		{Column{Type: encoding.IntType}, false},
+		{Column{Type: "varchar(20)", Strategy: encoding.HashStrategy}, false},
+	}
+	for _, test := range tests {
+		if result := reusesOriginalValues(test.column); result != test.expected {
+			t.Errorf("%v %q: got %v, expected %v", test.column.Type, test.column.Strategy, result, test.expected)
+		}
+	}
+}
+
+func TestScanLeaksSkipsReusedValues(t *testing.T) {
+	originalDb, _ := openRecordingDb(t)
+	destinationDb, _ := openRecordingDb(t)
+	model := map[string][]Column{
+		"users": {
+			{Name: "id", Type: encoding.IntType, IsPrimaryKey: true},
+			{Name: "email", Type: "varchar(40)", NeedToObfuscate: true},
+			{Name: "status", Type: "enum('active','blocked')", NeedToObfuscate: true},
+			{Name: "salary", Type: encoding.IntType, NeedToObfuscate: true, Strategy: encoding.RoundStrategy,
+				Params: map[string]string{encoding.StepParam: "1000"}},
+		},
+	}
+	report := ScanLeaks(model, originalDb, destinationDb)
+	if !report.Passed || len(report.Columns) != 1 || report.Columns[0].Column != "email" {
+		t.Errorf("only email must be scanned, got %+v", report)
+	}
+}
@@ -1,16 +1,59 @@
 package obfuscating
 
+//either Profile or other fields are required, only Schema and timeouts can be set with Profile
 type ConnectionInfo struct {
-	User     string `binding:"required"`
-	Password string `binding:"required"`
-	Host     string `binding:"required"`
-	Schema   string `binding:"required"`
+	Profile  string `json:",omitempty"`
+	User     string
+	Password string `json:",omitempty"`
+	Host     string
+	Schema   string
+	//unix socket path, used instead of Host if it's set
+	Socket string `json:",omitempty"`
+	//disabled if empty, see TLS modes
+	TlsMode       string `json:",omitempty"`
+	TlsCa         string `json:",omitempty"` //path to PEM file
+	TlsCert       string `json:",omitempty"` //path to PEM file
+	TlsKey        string `json:",omitempty"` //path to PEM file
+	TlsServerName string `json:",omitempty"`
+	//durations, e.g. "10s"
+	ConnectTimeout string `json:",omitempty"`
+	ReadTimeout    string `json:",omitempty"`
+	WriteTimeout   string `json:",omitempty"`
+	//utf8mb4 if empty
+	Charset   string `json:",omitempty"`
+	Collation string `json:",omitempty"`
+	//extra DSN parameters, only the ones which don't affect security and values are allowed, see allowedDsnParams
+	Params map[string]string `json:",omitempty"`
 }
 
 type ObfuscateRequest struct {
-	Model       map[string][]Column `binding:"required"`
-	Origin      ConnectionInfo      `binding:"required"`
-	Destination ConnectionInfo      `binding:"required"`
+	//either Model or Policy is required
+	Model         map[string][]Column
+	Policy        string
+	PolicyVersion int            //latest if 0
+	Origin        ConnectionInfo `binding:"required"`
+	Destination   ConnectionInfo `binding:"required"`
+	//only plan is returned if it's true
+	DryRun bool
+	//fail if empty
+	ConflictMode string
+	//overrides ConflictMode for particular tables
+	TableConflictModes map[string]string
+	//row counts, checksums and foreign keys are verified after copying otherwise
+	SkipVerification bool
+	//original values of obfuscated columns are searched in destination otherwise
+	SkipLeakScan bool
+}
+
+type PreviewRequest struct {
+	//either Model or Policy is required
+	Model         map[string][]Column
+	Policy        string
+	PolicyVersion int            //latest if 0
+	Origin        ConnectionInfo `binding:"required"`
+	Table         string         `binding:"required"`
+	Limit         int
+	MaskOriginals bool
 }
 
 type Column struct {
@@ -19,4 +62,10 @@ type Column struct {
 	//can obfuscate in obfuscating context, need to obfuscate in request context
 	NeedToObfuscate bool `binding:"required"`
 	IsPrimaryKey    bool `binding:"required"`
+	//UNI column which isn't in foreign keys, it's copied verbatim unless NeedToObfuscate is set in request,
+	//then it's obfuscated without collisions, see uniqueness.go
+	Unique bool `json:",omitempty"`
+	//empty strategy means obfuscating by column type
+	Strategy string            `json:",omitempty"`
+	Params   map[string]string `json:",omitempty"`
 }
@@ -2,6 +2,7 @@ package obfuscating
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	_ "github.com/go-sql-driver/mysql"
 	"log"
@@ -15,34 +16,46 @@ const (
 	selectBySlicesQuery = "SELECT * FROM %v ORDER BY %v LIMIT %v OFFSET %v;"
 )
 
-func ObfuscateSchema(model map[string][]Column, processId string,
-	originalDbConnInfo, destinationDbConnInfo ConnectionInfo) {
-	originalDb, err := openDbConnection(originalDbConnInfo)
+func ObfuscateSchema(request ObfuscateRequest, processId string) {
+	defer finishProcess(processId)
+
+	originalDb, err := openDbConnection(request.Origin)
+	if err != nil {
+		writeError(processId, err)
+		return
+	}
+	destinationDb, err := openDbConnection(request.Destination)
 	if err != nil {
 		writeError(processId, err)
 		return
 	}
-	destinationDb, err := openDbConnection(destinationDbConnInfo)
+
+	tables, err := getSortedTables(originalDb, request.Origin.Schema)
 	if err != nil {
 		writeError(processId, err)
 		return
 	}
 
-	tables, err := getSortedTables(originalDb, originalDbConnInfo.Schema)
+	audit, err := initAudit(originalDb, request.Model)
 	if err != nil {
 		writeError(processId, err)
 		return
 	}
+	for table, tableAudit := range audit {
+		writeTableAudit(processId, table, tableAudit)
+	}
 
 	for _, table := range tables {
 		println(table + " copying started")
 
-		err := createTableCopy(originalDb, destinationDb, table)
+		mode := request.getConflictMode(table)
+		err := prepareDestinationTable(originalDb, destinationDb, table, request.Destination.Schema, mode)
 		if err != nil {
 			writeError(processId, err)
 			return
 		}
-		err = obfuscateTable(model[table], table, originalDb, destinationDb)
+		err = obfuscateTable(request.Model[table], table, mode, audit[table], originalDb, destinationDb)
+		writeTableAudit(processId, table, audit[table])
 		if err != nil {
 			writeError(processId, err)
 			return
@@ -52,22 +65,45 @@ func ObfuscateSchema(model map[string][]Column, processId string,
 
 		println(table + " copying finished")
 	}
+
+	if !request.SkipVerification {
+		println("verification started")
+		report := VerifyCopy(request.Model, originalDb, destinationDb, request.Destination.Schema)
+		writeVerification(processId, report)
+		println("verification finished")
+	}
+
+	if !request.SkipLeakScan {
+		println("leak scan started")
+		report := ScanLeaks(request.Model, originalDb, destinationDb)
+		writeLeakReport(processId, report)
+		println("leak scan finished")
+	}
 }
 
-func obfuscateTable(model []Column, tableName string, originalDb, destinationDb *sql.DB) error {
+func obfuscateTable(model []Column, tableName, mode string, audit map[string]*ColumnAudit,
+	originalDb, destinationDb *sql.DB) error {
+	model, err := fillDistributions(originalDb, tableName, model)
+	if err != nil {
+		return err
+	}
+
 	//locking writing to table by all sessions until unlocking below
-	lockTableQuery := fmt.Sprintf("LOCK TABLES %v READ;", tableName)
-	_, err := originalDb.Exec(lockTableQuery)
+	_, err = originalDb.Exec(getLockTableQuery(tableName))
 	if err != nil {
 		return err
 	}
 
-	orderByValues := getOrderByValues(model)
+	shuffles, err := getShuffledColumns(originalDb, tableName, model, 0)
+	if err != nil {
+		return err
+	}
+	unique := getUniqueValues(model)
+
 	i := 0
 	for {
 		limit := config.GetConfig().Obfuscator.SliceSize
-		selectQuery := fmt.Sprintf(selectBySlicesQuery, tableName, orderByValues, limit, limit*i)
-		values, err := getValues(selectQuery, originalDb)
+		values, err := getValues(getSelectSliceQuery(tableName, model, limit, limit*i), originalDb)
 		if err != nil {
 			return err
 		}
@@ -75,7 +111,7 @@ func obfuscateTable(model []Column, tableName string, originalDb, destinationDb
 			break
 		}
 
-		err = obfuscateSlice(values, model, tableName, destinationDb)
+		err = obfuscateSlice(values, limit*i, model, tableName, mode, audit, shuffles, unique, destinationDb)
 		if err != nil {
 			return err
 		}
@@ -127,46 +163,93 @@ func getValues(selectQuery string, db *sql.DB) ([]map[string]*interface{}, error
 	return result, nil
 }
 
-func obfuscateSlice(data []map[string]*interface{}, model []Column, tableName string, db *sql.DB) error {
+//offset is the number of the first row of the slice in the table
+func obfuscateSlice(data []map[string]*interface{}, offset int, model []Column, tableName, mode string,
+	audit map[string]*ColumnAudit, shuffles shuffledColumns, unique uniqueValues, db *sql.DB) error {
 	if len(data) == 0 {
 		return nil
 	}
 
-	valuesTemplate, columnNames := getInsertsTemplate(model, len(data))
-	insertQuery := "INSERT INTO " + tableName + " (" + columnNames + ") VALUES " + valuesTemplate + ";"
-	var params = make([]interface{}, len(model)*len(data))
-	i := 0
-	for _, row := range data {
+	insertQuery := getInsertQuery(tableName, model, len(data), mode)
+	var params []interface{}
+	for i, row := range data {
+		shuffledRow, err := shuffles.apply(row, offset+i)
+		if err != nil {
+			return err
+		}
+		values, errs := obfuscateRow(shuffledRow, model, unique)
+		for columnName, err := range errs {
+			if errors.Is(err, encoding.ErrNoFallback) {
+				return fmt.Errorf("table %v, column %v: %w", tableName, columnName, err)
+			}
+			log.Printf("Error: Encoding value was failed. Table: %v, Column: %v. %v",
+				tableName, columnName, err.Error())
+			audit[columnName].Fallbacks++
+			audit[columnName].LastError = err.Error()
+		}
 		for _, column := range model {
-			valueToInsert := row[column.Name]
-			if column.NeedToObfuscate {
-				obfuscatedValue, err := encoding.ObfuscateValue(valueToInsert, column.Type)
-				if err != nil {
-					log.Printf("Error: Encoding value was failed. Table: %v, Column: %v. %v",
-						tableName, column.Name, err.Error())
-					params[i] = valueToInsert
-					err = nil
-				} else {
-					params[i] = obfuscatedValue
-				}
-			} else {
-				params[i] = valueToInsert
+			audit[column.Name].Rows++
+			if value := row[column.Name]; value == nil || *value == nil {
+				audit[column.Name].Nulls++
 			}
-			i++
 		}
+		params = append(params, values...)
 	}
-	_, err := db.Exec(insertQuery, params...)
+	//pseudonyms mustn't be in destination without mappings in the vault
+	err := encoding.FlushPseudonyms()
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(insertQuery, params...)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+//returns values in model order, original value is kept if encoding was failed,
+//errors which are encoding.ErrNoFallback must fail the job instead
+func obfuscateRow(row map[string]*interface{}, model []Column, unique uniqueValues) ([]interface{}, map[string]error) {
+	values := make([]interface{}, len(model))
+	errs := make(map[string]error)
+	for i, column := range model {
+		valueToInsert := row[column.Name]
+		//shuffled values are already in the row, tuples and templates are filled after other columns below
+		if column.NeedToObfuscate && column.Strategy != encoding.ShuffleStrategy &&
+			column.Strategy != encoding.TemplateStrategy && column.Strategy != encoding.TupleStrategy {
+			generate := func() (interface{}, error) {
+				//scripts can use other values of the row
+				if column.Strategy == encoding.ScriptStrategy {
+					return encoding.ObfuscateValueWithScript(valueToInsert, column.Type, column.Params, row)
+				}
+				return encoding.ObfuscateValueWithStrategy(valueToInsert, column.Type, column.Strategy, column.Params)
+			}
+			obfuscatedValue, err := generate()
+			if err == nil {
+				obfuscatedValue, err = unique.add(column, obfuscatedValue, generate)
+			}
+			if err != nil {
+				errs[column.Name] = err
+				if !errors.Is(err, encoding.ErrNoFallback) {
+					values[i] = valueToInsert
+				}
+			} else {
+				values[i] = obfuscatedValue
+			}
+		} else {
+			values[i] = valueToInsert
+		}
+	}
+	fillTuples(row, model, values, unique, errs)
+	renderTemplates(row, model, values, unique, errs)
+	return values, errs
+}
+
 func getInsertsTemplate(columns []Column, rowsCount int) (valuesTemplate string, columnsTemplate string) {
 	var columnNames []string
 	var valueParams []string
 	for _, column := range columns {
-		columnNames = append(columnNames, column.Name)
+		columnNames = append(columnNames, quoteIdentifier(column.Name))
 		valueParams = append(valueParams, "?")
 	}
 	valueSlice := "(" + strings.Join(valueParams, ",") + ")"
@@ -184,21 +267,10 @@ func getOrderByValues(columns []Column) string {
 	for _, column := range columns {
 		//UNI key doesn't guarantee that there's all unique columns are showed
 		if column.IsPrimaryKey {
-			orderByValues = append(orderByValues, column.Name)
+			orderByValues = append(orderByValues, quoteIdentifier(column.Name))
 		}
 	}
 	//checking that at least one column is primary key is carried out during getting columns info and validating model
 	orderByValuesString := strings.Join(orderByValues, ",")
 	return orderByValuesString
 }
-
-func openDbConnection(connInfo ConnectionInfo) (*sql.DB, error) {
-	url := fmt.Sprintf("%v:%v@tcp(%v)/%v?charset=utf8&interpolateParams=true",
-		connInfo.User, connInfo.Password, connInfo.Host, connInfo.Schema)
-	db, err := sql.Open(MysqlDriverName, url)
-	if err != nil {
-		return nil, err
-	}
-	db.SetMaxOpenConns(config.GetConfig().Db.MaxOpenConnections)
-	return db, nil
-}
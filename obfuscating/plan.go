@@ -0,0 +1,212 @@
+package obfuscating
+
+import (
+	"database/sql"
+	"fmt"
+	"obfuscator/encoding"
+	"regexp"
+	"strings"
+)
+
+//table actions, conflict modes are used for existing tables
+const (
+	CreateAction = "create"
+)
+
+const (
+	allPrivileges = "ALL PRIVILEGES"
+)
+
+var (
+	originPrivileges      = []string{"SELECT", "LOCK TABLES"}
+	destinationPrivileges = []string{"CREATE", "INSERT"}
+	conflictPrivileges    = map[string][]string{
+		DropMode:     {"DROP"},
+		TruncateMode: {"DROP"},
+		UpsertMode:   {"UPDATE"},
+	}
+	grantPattern = regexp.MustCompile("^GRANT (.+) ON (\\S+) TO ")
+)
+
+type ObfuscationPlan struct {
+	Tables          []TablePlan
+	TotalRows       int64
+	TotalDataLength int64
+	Problems        []string
+}
+
+type TablePlan struct {
+	Table string
+	//estimation from information_schema, can be inaccurate for InnoDB
+	EstimatedRows int64
+	DataLength    int64
+	Action        string
+	Strategies    map[string]string `json:",omitempty"`
+}
+
+type tableStatus struct {
+	rows       int64
+	dataLength int64
+}
+
+//plans obfuscation of the validated model without writing anything
+func PlanObfuscation(request ObfuscateRequest) (ObfuscationPlan, error) {
+	plan := ObfuscationPlan{Tables: []TablePlan{}}
+	originalDbConnInfo, destinationDbConnInfo := request.Origin, request.Destination
+
+	originalDb, err := openDbConnection(originalDbConnInfo)
+	if err != nil {
+		return plan, err
+	}
+	defer originalDb.Close()
+	destinationDb, err := openDbConnection(destinationDbConnInfo)
+	if err != nil {
+		return plan, err
+	}
+	defer destinationDb.Close()
+
+	tables, err := getSortedTables(originalDb, originalDbConnInfo.Schema)
+	if err != nil {
+		return plan, err
+	}
+	statuses, err := getTableStatuses(originalDb, originalDbConnInfo.Schema)
+	if err != nil {
+		return plan, err
+	}
+
+	err = destinationDb.Ping()
+	if err != nil {
+		plan.Problems = append(plan.Problems, fmt.Sprintf("destination isn't reachable: %v", err))
+		return plan, nil
+	}
+	existingTables, err := getTables(destinationDb, destinationDbConnInfo.Schema)
+	if err != nil {
+		return plan, err
+	}
+	existing := make(map[string]bool)
+	for _, table := range existingTables {
+		existing[table] = true
+	}
+
+	plan.Problems = append(plan.Problems, checkPrivileges(originalDb, "origin", originalDbConnInfo.Schema, originPrivileges)...)
+	requiredPrivileges := append([]string{}, destinationPrivileges...)
+	for _, table := range tables {
+		if existing[table] {
+			requiredPrivileges = append(requiredPrivileges, conflictPrivileges[request.getConflictMode(table)]...)
+		}
+	}
+	plan.Problems = append(plan.Problems, checkPrivileges(destinationDb, "destination", destinationDbConnInfo.Schema, requiredPrivileges)...)
+
+	for _, table := range tables {
+		tablePlan := TablePlan{
+			Table:         table,
+			EstimatedRows: statuses[table].rows,
+			DataLength:    statuses[table].dataLength,
+			Action:        CreateAction,
+			Strategies:    getStrategies(request.Model[table]),
+		}
+		if existing[table] {
+			tablePlan.Action = request.getConflictMode(table)
+			if tablePlan.Action == FailMode {
+				plan.Problems = append(plan.Problems, fmt.Sprintf("table %v already exists in destination", table))
+			}
+		}
+		plan.TotalRows += tablePlan.EstimatedRows
+		plan.TotalDataLength += tablePlan.DataLength
+		plan.Tables = append(plan.Tables, tablePlan)
+	}
+	return plan, nil
+}
+
+func getStrategies(model []Column) map[string]string {
+	strategies := make(map[string]string)
+	for _, column := range model {
+		if !column.NeedToObfuscate {
+			continue
+		}
+		if column.Strategy == encoding.DefaultStrategy {
+			strategies[column.Name] = "default"
+		} else {
+			strategies[column.Name] = column.Strategy
+		}
+	}
+	return strategies
+}
+
+func getTableStatuses(db *sql.DB, schemaName string) (map[string]tableStatus, error) {
+	rows, err := db.Query("SELECT table_name, IFNULL(table_rows, 0), IFNULL(data_length, 0)"+
+		" FROM information_schema.tables WHERE table_schema = ?;", schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	statuses := make(map[string]tableStatus)
+	for rows.Next() {
+		var table string
+		var status tableStatus
+		err = rows.Scan(&table, &status.rows, &status.dataLength)
+		if err != nil {
+			return nil, err
+		}
+		statuses[table] = status
+	}
+	return statuses, nil
+}
+
+//checks only global and schema level grants, so table level grants are reported as missing
+func checkPrivileges(db *sql.DB, dbName, schemaName string, required []string) []string {
+	grants, err := showGrants(db)
+	if err != nil {
+		return []string{fmt.Sprintf("can't check privileges on %v: %v", dbName, err)}
+	}
+	granted := getGrantedPrivileges(grants, schemaName)
+	var problems []string
+	checked := make(map[string]bool)
+	for _, privilege := range required {
+		if checked[privilege] {
+			continue
+		}
+		checked[privilege] = true
+		if !granted[privilege] && !granted[allPrivileges] {
+			problems = append(problems, fmt.Sprintf("%v user hasn't %v privilege on schema %v", dbName, privilege, schemaName))
+		}
+	}
+	return problems
+}
+
+func showGrants(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("SHOW GRANTS FOR CURRENT_USER();")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var grants []string
+	for rows.Next() {
+		var grant string
+		err = rows.Scan(&grant)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+func getGrantedPrivileges(grants []string, schemaName string) map[string]bool {
+	granted := make(map[string]bool)
+	for _, grant := range grants {
+		matches := grantPattern.FindStringSubmatch(grant)
+		if matches == nil {
+			continue
+		}
+		target := strings.ReplaceAll(matches[2], "`", "")
+		target = strings.ReplaceAll(target, "\\_", "_")
+		if target != "*.*" && target != schemaName+".*" {
+			continue
+		}
+		for _, privilege := range strings.Split(matches[1], ",") {
+			granted[strings.TrimSpace(privilege)] = true
+		}
+	}
+	return granted
+}
@@ -0,0 +1,126 @@
+package obfuscating
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultPreviewLimit = 10
+	maxPreviewLimit     = 100
+	maskRune            = '*'
+)
+
+type PreviewRow struct {
+	Original   map[string]interface{}
+	Obfuscated map[string]interface{}
+	Errors     map[string]string `json:",omitempty"`
+}
+
+//reads first rows of the table from origin and obfuscates them without writing to destination
+func PreviewTable(model map[string][]Column, tableName string, limit int, maskOriginals bool,
+	dbConnInfo ConnectionInfo) ([]PreviewRow, error) {
+	tableModel, contains := model[tableName]
+	if !contains {
+		return nil, fmt.Errorf("model hasn't table %v", tableName)
+	}
+	if limit <= 0 {
+		limit = defaultPreviewLimit
+	}
+	if limit > maxPreviewLimit {
+		limit = maxPreviewLimit
+	}
+
+	db, err := openDbConnection(dbConnInfo)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	tableModel, err = fillDistributions(db, tableName, tableModel)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := getValues(getSelectSliceQuery(tableName, tableModel, limit, 0), db)
+	if err != nil {
+		return nil, err
+	}
+
+	//values are shuffled only between previewed rows not to read the whole column
+	shuffles, err := getShuffledColumns(db, tableName, tableModel, limit)
+	if err != nil {
+		return nil, err
+	}
+	unique := getUniqueValues(tableModel)
+
+	result := []PreviewRow{}
+	for i, row := range data {
+		shuffledRow, err := shuffles.apply(row, i)
+		if err != nil {
+			return nil, err
+		}
+		values, errs := obfuscateRow(shuffledRow, tableModel, unique)
+		previewRow := getPreviewRow(row, values, errs, tableModel, maskOriginals)
+		result = append(result, previewRow)
+	}
+	return result, nil
+}
+
+func getPreviewRow(row map[string]*interface{}, values []interface{}, errs map[string]error, model []Column,
+	maskOriginals bool) PreviewRow {
+	previewRow := PreviewRow{
+		Original:   make(map[string]interface{}),
+		Obfuscated: make(map[string]interface{}),
+	}
+	for i, column := range model {
+		original := toDisplayValue(row[column.Name])
+		obfuscated := toDisplayValue(values[i])
+		if maskOriginals && column.NeedToObfuscate {
+			original = maskValue(original)
+			//failed column falls back to the original value
+			if _, failed := errs[column.Name]; failed {
+				obfuscated = maskValue(obfuscated)
+			}
+		}
+		previewRow.Original[column.Name] = original
+		previewRow.Obfuscated[column.Name] = obfuscated
+	}
+	if len(errs) > 0 {
+		previewRow.Errors = make(map[string]string)
+		for columnName, err := range errs {
+			previewRow.Errors[columnName] = err.Error()
+		}
+	}
+	return previewRow
+}
+
+//mysql driver returns most of values as []byte which are marshalled to base64
+func toDisplayValue(value interface{}) interface{} {
+	if pointer, ok := value.(*interface{}); ok {
+		if pointer == nil {
+			return nil
+		}
+		value = *pointer
+	}
+	if bytes, ok := value.([]byte); ok {
+		if utf8.Valid(bytes) {
+			return string(bytes)
+		}
+		return fmt.Sprintf("0x%X", bytes)
+	}
+	return value
+}
+
+//keeps first character, so masked values are still distinguishable
+func maskValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	runes := []rune(fmt.Sprintf("%v", value))
+	if len(runes) <= 1 {
+		return string(maskRune)
+	}
+	return string(runes[0]) + strings.Repeat(string(maskRune), len(runes)-1)
+}
@@ -0,0 +1,44 @@
+package obfuscating
+
+import (
+	"errors"
+	"obfuscator/encoding"
+	"testing"
+)
+
+func TestGetPreviewRow(t *testing.T) {
+	model := []Column{
+		{Name: "id", Type: encoding.IntType, IsPrimaryKey: true},
+		{Name: "email", Type: "varchar(40)", NeedToObfuscate: true},
+		{Name: "card", Type: "varchar(20)", NeedToObfuscate: true, Strategy: encoding.MaskStrategy},
+	}
+	var id, email, card interface{} = int64(1), []byte("john@mail.com"), []byte("4111111111111111")
+	row := map[string]*interface{}{"id": &id, "email": &email, "card": &card}
+	//card strategy failed and kept the original value
+	values := []interface{}{&id, "jane@mail.com", &card}
+	errs := map[string]error{"card": errors.New("failed")}
+
+	tests := []struct {
+		maskOriginals bool
+		column        string
+		original      interface{}
+		obfuscated    interface{}
+	}{
+		{false, "card", "4111111111111111", "4111111111111111"},
+		{false, "email", "john@mail.com", "jane@mail.com"},
+		{true, "id", int64(1), int64(1)},
+		{true, "email", "j************", "jane@mail.com"},
+		{true, "card", "4***************", "4***************"},
+	}
+	for _, test := range tests {
+		previewRow := getPreviewRow(row, values, errs, model, test.maskOriginals)
+		original, obfuscated := previewRow.Original[test.column], previewRow.Obfuscated[test.column]
+		if original != test.original || obfuscated != test.obfuscated {
+			t.Errorf("%v %v: got %v and %v, expected %v and %v", test.maskOriginals, test.column, original,
+				obfuscated, test.original, test.obfuscated)
+		}
+		if previewRow.Errors["card"] != "failed" {
+			t.Errorf("unexpected errors %v", previewRow.Errors)
+		}
+	}
+}
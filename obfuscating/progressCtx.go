@@ -2,6 +2,8 @@ package obfuscating
 
 import (
 	"github.com/google/uuid"
+	"sync"
+	"time"
 )
 
 type ObfuscationProgress struct {
@@ -9,11 +11,23 @@ type ObfuscationProgress struct {
 	FinishedCount int
 	TotalCount    int
 	Error         string
+	Verification  *VerificationReport `json:",omitempty"`
+	LeakScan      *LeakReport         `json:",omitempty"`
+	StartedBy     string
+	Policy        string `json:",omitempty"`
+	PolicyVersion int    `json:",omitempty"`
+	Started       time.Time
+	Finished      *time.Time `json:",omitempty"`
+	model         map[string][]Column
+	audit         map[string]map[string]ColumnAudit
 }
 
-var progressCtx = make(map[string]ObfuscationProgress)
+var (
+	progressCtx   = make(map[string]ObfuscationProgress)
+	progressMutex sync.RWMutex
+)
 
-func InitProcess(totalCount int) (string, error) {
+func InitProcess(request ObfuscateRequest, startedBy string) (string, error) {
 	processUuid, err := uuid.NewRandom()
 	if err != nil {
 		return "", err
@@ -22,28 +36,81 @@ func InitProcess(totalCount int) (string, error) {
 	var progressEntry ObfuscationProgress
 	progressEntry.ProcessId = processId
 	progressEntry.FinishedCount = 0
-	progressEntry.TotalCount = totalCount
+	progressEntry.TotalCount = len(request.Model)
+	progressEntry.StartedBy = startedBy
+	progressEntry.Policy = request.Policy
+	progressEntry.PolicyVersion = request.PolicyVersion
+	progressEntry.Started = time.Now().UTC()
+	progressEntry.model = request.Model
+	progressEntry.audit = make(map[string]map[string]ColumnAudit)
+	progressMutex.Lock()
+	defer progressMutex.Unlock()
 	progressCtx[processId] = progressEntry
 	return processId, err
 }
 
 func GetProcessCtx(processId string) (ObfuscationProgress, bool) {
+	progressMutex.RLock()
+	defer progressMutex.RUnlock()
 	progressEntry, exists := progressCtx[processId]
 	return progressEntry, exists
 }
 
 func EmptyProgressCtx() {
+	progressMutex.Lock()
+	defer progressMutex.Unlock()
 	progressCtx = make(map[string]ObfuscationProgress)
 }
 
-func increaseFinished(processId string) {
-	entry := progressCtx[processId]
-	entry.FinishedCount = entry.FinishedCount + 1
+//progress context can be emptied while process is running, removed entries aren't recreated
+func updateProgress(processId string, update func(entry *ObfuscationProgress)) {
+	progressMutex.Lock()
+	defer progressMutex.Unlock()
+	entry, exists := progressCtx[processId]
+	if !exists {
+		return
+	}
+	update(&entry)
 	progressCtx[processId] = entry
 }
 
+func increaseFinished(processId string) {
+	updateProgress(processId, func(entry *ObfuscationProgress) {
+		entry.FinishedCount = entry.FinishedCount + 1
+	})
+}
+
 func writeError(processId string, err error) {
-	entry := progressCtx[processId]
-	entry.Error = err.Error()
-	progressCtx[processId] = entry
+	updateProgress(processId, func(entry *ObfuscationProgress) {
+		entry.Error = err.Error()
+	})
+}
+
+func writeVerification(processId string, report VerificationReport) {
+	updateProgress(processId, func(entry *ObfuscationProgress) {
+		entry.Verification = &report
+	})
+}
+
+func writeLeakReport(processId string, report LeakReport) {
+	updateProgress(processId, func(entry *ObfuscationProgress) {
+		entry.LeakScan = &report
+	})
+}
+
+func writeTableAudit(processId, table string, audit map[string]*ColumnAudit) {
+	tableAudit := make(map[string]ColumnAudit)
+	for column, columnAudit := range audit {
+		tableAudit[column] = *columnAudit
+	}
+	updateProgress(processId, func(entry *ObfuscationProgress) {
+		entry.audit[table] = tableAudit
+	})
+}
+
+func finishProcess(processId string) {
+	updateProgress(processId, func(entry *ObfuscationProgress) {
+		finished := time.Now().UTC()
+		entry.Finished = &finished
+	})
 }
@@ -0,0 +1,32 @@
+package obfuscating
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWriteProgress(t *testing.T) {
+	processId, err := InitProcess(ObfuscateRequest{Model: map[string][]Column{"users": nil}}, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	increaseFinished(processId)
+	writeTableAudit(processId, "users", map[string]*ColumnAudit{"email": {Column: "email", Rows: 2}})
+	finishProcess(processId)
+	entry, exists := GetProcessCtx(processId)
+	if !exists || entry.FinishedCount != 1 || entry.Finished == nil || entry.audit["users"]["email"].Rows != 2 {
+		t.Errorf("unexpected progress %+v", entry)
+	}
+
+	//writers of running process mustn't recreate emptied entry or panic on it
+	EmptyProgressCtx()
+	increaseFinished(processId)
+	writeError(processId, errors.New("failed"))
+	writeVerification(processId, VerificationReport{})
+	writeLeakReport(processId, LeakReport{})
+	writeTableAudit(processId, "users", map[string]*ColumnAudit{})
+	finishProcess(processId)
+	if _, exists = GetProcessCtx(processId); exists {
+		t.Errorf("emptied progress mustn't be recreated")
+	}
+}
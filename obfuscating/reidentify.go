@@ -0,0 +1,125 @@
+package obfuscating
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+	"obfuscator/config"
+	"obfuscator/encoding"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const reidentificationsFileName = "reidentifications.jsonl"
+
+var reidentificationsMutex sync.Mutex
+
+type ReidentifyRequest struct {
+	//type and params of the column obfuscated by fpe strategy as they are in the model or policy
+	Type   string `binding:"required"`
+	Params map[string]string
+	Values []string `binding:"required"`
+	//why original values are needed, stored in audit log
+	Reason string `binding:"required"`
+}
+
+type ReidentifiedValue struct {
+	Value    string
+	Original string `json:",omitempty"`
+	Error    string `json:",omitempty"`
+}
+
+//original values aren't stored
+type ReidentificationRecord struct {
+	Time   time.Time
+	Login  string
+	Reason string
+	Type   string
+	Params map[string]string `json:",omitempty"`
+	Values []string
+}
+
+//decrypts values obfuscated by fpe strategy, nothing is decrypted if the request can't be audited
+func Reidentify(request ReidentifyRequest, login string) ([]ReidentifiedValue, error) {
+	err := writeReidentificationRecord(ReidentificationRecord{
+		Time:   time.Now(),
+		Login:  login,
+		Reason: request.Reason,
+		Type:   request.Type,
+		Params: request.Params,
+		Values: request.Values,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("%v re-identified %v values, reason: %v", login, len(request.Values), request.Reason)
+
+	result := []ReidentifiedValue{}
+	for _, value := range request.Values {
+		reidentified := ReidentifiedValue{Value: value}
+		original, err := encoding.RevealFormatPreserving(value, request.Type, request.Params)
+		if err != nil {
+			reidentified.Error = err.Error()
+		} else {
+			reidentified.Original = original
+		}
+		result = append(result, reidentified)
+	}
+	return result, nil
+}
+
+func GetReidentificationRecords() ([]ReidentificationRecord, error) {
+	reidentificationsMutex.Lock()
+	defer reidentificationsMutex.Unlock()
+	file, err := os.Open(getReidentificationsFilePath())
+	if os.IsNotExist(err) {
+		return []ReidentificationRecord{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records := []ReidentificationRecord{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		var record ReidentificationRecord
+		err = json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+func writeReidentificationRecord(record ReidentificationRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	reidentificationsMutex.Lock()
+	defer reidentificationsMutex.Unlock()
+	path := getReidentificationsFilePath()
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(data, '\n'))
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func getReidentificationsFilePath() string {
+	return filepath.Join(config.GetConfig().Storage.Dir, reidentificationsFileName)
+}
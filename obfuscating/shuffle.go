@@ -0,0 +1,184 @@
+package obfuscating
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"obfuscator/encoding"
+)
+
+//column -> values permuted across rows, in the order of rows read by primary key
+type shuffledColumns map[string][]interface{}
+
+//first pass over the table: values of shuffled columns are read for all rows, so they're kept in memory,
+//only first rows are read and permuted if limit is positive, e.g. for preview
+func getShuffledColumns(db *sql.DB, tableName string, model []Column, limit int) (shuffledColumns, error) {
+	result := make(shuffledColumns)
+	orderByValues := getOrderByValues(model)
+	//set -> permutation of rows shared by columns of the set and its group column
+	permutations := make(map[string][]int)
+	setGroups := make(map[string]string)
+	for _, column := range model {
+		if !column.NeedToObfuscate || column.Strategy != encoding.ShuffleStrategy {
+			continue
+		}
+		groupColumn := column.Params[encoding.GroupByParam]
+		if groupColumn != "" && !hasColumn(model, groupColumn) {
+			return nil, fmt.Errorf("table %v hasn't column %v to group shuffled values", tableName, groupColumn)
+		}
+		set := column.Params[encoding.ShuffleSetParam]
+		permutation, exists := permutations[set]
+		if set != "" && exists && setGroups[set] != groupColumn {
+			return nil, fmt.Errorf("columns of shuffle set %v in table %v are grouped by different columns",
+				set, tableName)
+		}
+		if set == "" || !exists {
+			var err error
+			permutation, err = getPermutation(db, tableName, groupColumn, orderByValues, limit)
+			if err != nil {
+				return nil, err
+			}
+			if set != "" {
+				permutations[set] = permutation
+				setGroups[set] = groupColumn
+			}
+		}
+		values, err := getColumnValues(db, tableName, column.Name, orderByValues, limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != len(permutation) {
+			return nil, fmt.Errorf("table %v was changed while shuffling column %v", tableName, column.Name)
+		}
+		shuffled := make([]interface{}, len(values))
+		for i, index := range permutation {
+			shuffled[i] = values[index]
+		}
+		result[column.Name] = shuffled
+	}
+	return result, nil
+}
+
+//checks groups and sets of shuffled columns before the table is locked
+func validateShuffles(tableName string, model []Column) error {
+	setGroups := make(map[string]string)
+	for _, column := range model {
+		if !column.NeedToObfuscate || column.Strategy != encoding.ShuffleStrategy {
+			continue
+		}
+		groupColumn := column.Params[encoding.GroupByParam]
+		if groupColumn != "" && !hasColumn(model, groupColumn) {
+			return fmt.Errorf("table %v hasn't column %v to group shuffled values", tableName, groupColumn)
+		}
+		set := column.Params[encoding.ShuffleSetParam]
+		if set == "" {
+			continue
+		}
+		if group, exists := setGroups[set]; exists && group != groupColumn {
+			return fmt.Errorf("columns of shuffle set %v in table %v are grouped by different columns",
+				set, tableName)
+		}
+		setGroups[set] = groupColumn
+	}
+	return nil
+}
+
+//returns indexes of rows whose values are taken by rows in the order by primary key,
+//rows are permuted within groups of rows with the same value of group column if it's set
+func getPermutation(db *sql.DB, tableName, groupColumn, orderByValues string, limit int) ([]int, error) {
+	groupExpression := "NULL"
+	if groupColumn != "" {
+		groupExpression = quoteIdentifier(groupColumn)
+	}
+	rows, err := db.Query(getOrderedColumnQuery(tableName, groupExpression, orderByValues, limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	count := 0
+	//group -> indexes of rows
+	groups := make(map[string][]int)
+	for rows.Next() {
+		var group interface{}
+		err = rows.Scan(&group)
+		if err != nil {
+			return nil, err
+		}
+		groupKey := fmt.Sprintf("%T:%s", group, group)
+		groups[groupKey] = append(groups[groupKey], count)
+		count++
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]int, count)
+	for _, indexes := range groups {
+		permuted := make([]int, len(indexes))
+		copy(permuted, indexes)
+		rand.Shuffle(len(permuted), func(i, j int) {
+			permuted[i], permuted[j] = permuted[j], permuted[i]
+		})
+		for i, index := range indexes {
+			result[index] = permuted[i]
+		}
+	}
+	return result, nil
+}
+
+func getColumnValues(db *sql.DB, tableName, columnName, orderByValues string, limit int) ([]interface{}, error) {
+	rows, err := db.Query(getOrderedColumnQuery(tableName, quoteIdentifier(columnName), orderByValues, limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []interface{}
+	for rows.Next() {
+		var value interface{}
+		err = rows.Scan(&value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+func getOrderedColumnQuery(tableName, expression, orderByValues string, limit int) string {
+	query := fmt.Sprintf("SELECT %v FROM %v ORDER BY %v", expression, quoteIdentifier(tableName), orderByValues)
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %v", limit)
+	}
+	return query
+}
+
+//returns copy of the row with shuffled values, index is the number of the row in the table
+func (s shuffledColumns) apply(row map[string]*interface{}, index int) (map[string]*interface{}, error) {
+	if len(s) == 0 {
+		return row, nil
+	}
+	result := make(map[string]*interface{}, len(row))
+	for column, value := range row {
+		result[column] = value
+	}
+	for column, values := range s {
+		//rows were added after the first pass
+		if index >= len(values) {
+			return nil, fmt.Errorf("table was changed while shuffling column %v", column)
+		}
+		value := values[index]
+		result[column] = &value
+	}
+	return result, nil
+}
+
+func hasColumn(model []Column, name string) bool {
+	for _, column := range model {
+		if column.Name == name {
+			return true
+		}
+	}
+	return false
+}
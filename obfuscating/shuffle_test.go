@@ -0,0 +1,80 @@
+package obfuscating
+
+import (
+	"obfuscator/encoding"
+	"strings"
+	"testing"
+)
+
+func TestShuffledColumnsLimit(t *testing.T) {
+	model := []Column{
+		{Name: "id", Type: encoding.IntType, IsPrimaryKey: true},
+		{Name: "city", Type: "varchar(20)", NeedToObfuscate: true, Strategy: encoding.ShuffleStrategy,
+			Params: map[string]string{encoding.GroupByParam: "country"}},
+		{Name: "country", Type: "varchar(20)"},
+	}
+	for _, limit := range []int{0, 10} {
+		db, recorder := openRecordingDb(t)
+		if _, err := getShuffledColumns(db, "users", model, limit); err != nil {
+			t.Fatal(err)
+		}
+		queries := recorder.get()
+		if len(queries) != 2 {
+			t.Fatalf("expected permutation and values queries, got %v", queries)
+		}
+		for _, query := range queries {
+			if hasLimit := strings.HasSuffix(query.text, " LIMIT 10"); hasLimit != (limit > 0) {
+				t.Errorf("limit %v: unexpected query %q", limit, query.text)
+			}
+		}
+	}
+}
+
+func TestShuffledColumnsApply(t *testing.T) {
+	shuffles := shuffledColumns{"city": {"Paris", "Rome"}}
+	var id, city interface{} = 1, "Berlin"
+	row := map[string]*interface{}{"id": &id, "city": &city}
+	result, err := shuffles.apply(row, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *result["city"] != "Rome" || *result["id"] != 1 || *row["city"] != "Berlin" {
+		t.Errorf("unexpected shuffled row %v, original %v", *result["city"], *row["city"])
+	}
+	if _, err = shuffles.apply(row, 2); err == nil {
+		t.Errorf("row added after the first pass must fail")
+	}
+}
+
+func TestValidateShuffles(t *testing.T) {
+	shuffled := func(name string, params map[string]string) Column {
+		return Column{Name: name, Type: "varchar(20)", NeedToObfuscate: true, Strategy: encoding.ShuffleStrategy,
+			Params: params}
+	}
+	tests := []struct {
+		columns []Column
+		valid   bool
+	}{
+		{[]Column{shuffled("city", nil), shuffled("zip", nil)}, true},
+		{[]Column{shuffled("city", map[string]string{encoding.ShuffleSetParam: "address",
+			encoding.GroupByParam: "country"}),
+			shuffled("zip", map[string]string{encoding.ShuffleSetParam: "address", encoding.GroupByParam: "country"})},
+			true},
+		{[]Column{shuffled("city", map[string]string{encoding.GroupByParam: "region"})}, false},
+		{[]Column{shuffled("city", map[string]string{encoding.ShuffleSetParam: "address",
+			encoding.GroupByParam: "country"}),
+			shuffled("zip", map[string]string{encoding.ShuffleSetParam: "address"})}, false},
+	}
+	for i, test := range tests {
+		model := append([]Column{{Name: "id", Type: encoding.IntType, IsPrimaryKey: true},
+			{Name: "country", Type: "varchar(20)"}}, test.columns...)
+		err := validateShuffles("users", model)
+		if (err == nil) != test.valid {
+			t.Errorf("test %v: unexpected result %v", i, err)
+		}
+	}
+	if err := encoding.ValidateStrategy(encoding.ShuffleStrategy, "varchar(20)",
+		map[string]string{"groupby": "country"}); err == nil {
+		t.Errorf("unknown param must be rejected")
+	}
+}
@@ -0,0 +1,108 @@
+package obfuscating
+
+import (
+	"database/sql"
+	"fmt"
+	"obfuscator/config"
+	"obfuscator/detection"
+)
+
+//Column.Strategy holds suggested strategy
+type ColumnSuggestion struct {
+	Column
+	Kind       string
+	Confidence float64
+	Reasons    []string
+}
+
+type fullColumn struct {
+	RawColumn
+	Collation  *string
+	Privileges string
+	Comment    string
+}
+
+//classifies obfuscatable columns of the schema by names, comments and sample values
+func SuggestModel(dbConnInfo ConnectionInfo) (map[string][]ColumnSuggestion, error) {
+	db, err := openDbConnection(dbConnInfo)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	schemaInfo, err := GetSchemaInfo(dbConnInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	sampleSize := config.GetConfig().Detection.SampleSize
+	result := make(map[string][]ColumnSuggestion)
+	for table, columns := range schemaInfo {
+		comments, err := getColumnComments(db, table)
+		if err != nil {
+			return nil, err
+		}
+		var suggestions []ColumnSuggestion
+		for _, column := range columns {
+			suggestion := ColumnSuggestion{Column: column}
+			if column.NeedToObfuscate {
+				values, err := getSampleValues(db, table, column.Name, sampleSize)
+				if err != nil {
+					return nil, err
+				}
+				classified := detection.Classify(detection.ColumnSample{
+					Name:    column.Name,
+					Type:    column.Type,
+					Comment: comments[column.Name],
+					Values:  values,
+				})
+				suggestion.Strategy = classified.Strategy
+				suggestion.Kind = classified.Kind
+				suggestion.Confidence = classified.Confidence
+				suggestion.Reasons = classified.Reasons
+			}
+			suggestions = append(suggestions, suggestion)
+		}
+		result[table] = suggestions
+	}
+	return result, nil
+}
+
+func getColumnComments(db *sql.DB, tableName string) (map[string]string, error) {
+	rows, err := db.Query("SHOW FULL COLUMNS FROM " + quoteIdentifier(tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	comments := make(map[string]string)
+	for rows.Next() {
+		column := &fullColumn{}
+		err = rows.Scan(&column.Field, &column.Type, &column.Collation, &column.Null, &column.Key,
+			&column.Default, &column.Extra, &column.Privileges, &column.Comment)
+		if err != nil {
+			return nil, err
+		}
+		comments[column.Field] = column.Comment
+	}
+	return comments, nil
+}
+
+func getSampleValues(db *sql.DB, tableName, columnName string, limit int) ([]string, error) {
+	column := quoteIdentifier(columnName)
+	rows, err := db.Query(fmt.Sprintf("SELECT %v FROM %v WHERE %v IS NOT NULL LIMIT %v",
+		column, quoteIdentifier(tableName), column, limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var values []string
+	for rows.Next() {
+		var value string
+		err = rows.Scan(&value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
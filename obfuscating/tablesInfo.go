@@ -71,7 +71,7 @@ func getTablesWithDependencies(db *sql.DB, schemaName string) (map[string]map[st
 }
 
 func getTables(db *sql.DB, schemaName string) ([]string, error) {
-	rows, err := db.Query(fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema = '%v'; ", schemaName))
+	rows, err := db.Query("SELECT table_name FROM information_schema.tables WHERE table_schema = ?;", schemaName)
 	if err != nil {
 		return nil, err
 	}
@@ -88,8 +88,8 @@ func getTables(db *sql.DB, schemaName string) ([]string, error) {
 }
 
 func getDependencies(db *sql.DB, schemaName, tableName string) ([]string, error) {
-	rows, err := db.Query(fmt.Sprintf("SELECT referenced_table_name FROM information_schema.key_column_usage"+
-		" WHERE  referenced_table_schema = '%v' AND table_name = '%v'; ", schemaName, tableName))
+	rows, err := db.Query("SELECT referenced_table_name FROM information_schema.key_column_usage"+
+		" WHERE table_schema = ? AND referenced_table_schema = ? AND table_name = ?;", schemaName, schemaName, tableName)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,136 @@
+package obfuscating
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"obfuscator/config"
+	"obfuscator/encoding"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+//colliding value is generated again several times before disambiguation by suffix
+const maxUniqueAttempts = 10
+
+//truncated SHA-256 of the value, so memory doesn't depend on size of values,
+//false collision only makes the value generated again
+type uniqueKey [16]byte
+
+//column -> keys of values inserted into UNIQUE column during the table copying.
+//Rows which are in destination before copying (append and upsert modes) aren't taken into account
+type uniqueValues map[string]map[uniqueKey]bool
+
+//only UNI columns selected in request are tracked
+func getUniqueValues(model []Column) uniqueValues {
+	result := make(uniqueValues)
+	for _, column := range model {
+		//shuffled values are unique as origin ones
+		if column.Unique && column.NeedToObfuscate && column.Strategy != encoding.ShuffleStrategy {
+			result[column.Name] = make(map[uniqueKey]bool)
+		}
+	}
+	return result
+}
+
+//returns value which wasn't inserted into the column yet, colliding values are generated again
+//and disambiguated by "-n" suffix as the last resort
+func (u uniqueValues) add(column Column, value interface{}, generate func() (interface{}, error)) (interface{}, error) {
+	seen, exists := u[column.Name]
+	if !exists || isNull(value) {
+		return value, nil
+	}
+	if limit := config.GetConfig().Obfuscator.MaxUniqueValues; limit > 0 && len(seen) >= limit {
+		return nil, fmt.Errorf("column %v has more than %v unique values, see obfuscator.maxUniqueValues in config",
+			column.Name, limit)
+	}
+	var err error
+	for attempt := 0; attempt < maxUniqueAttempts; attempt++ {
+		key := getUniqueKey(value, column.Type)
+		if !seen[key] {
+			seen[key] = true
+			return value, nil
+		}
+		value, err = generate()
+		if err != nil {
+			return nil, err
+		}
+		//unique index allows any count of NULLs
+		if isNull(value) {
+			return value, nil
+		}
+	}
+
+	if !encoding.IsStringType(column.Type) {
+		return nil, fmt.Errorf("can't generate unique value of column %v", column.Name)
+	}
+	//collisions are possible with the values inserted before only, so count of them is enough
+	for n := 2; n <= len(seen)+2; n++ {
+		candidate, err := disambiguate(toUniqueString(value), n, column.Type)
+		if err != nil {
+			return nil, err
+		}
+		key := getUniqueKey(candidate, column.Type)
+		if !seen[key] {
+			seen[key] = true
+			return candidate, nil
+		}
+	}
+	return nil, fmt.Errorf("can't generate unique value of column %v", column.Name)
+}
+
+//appends suffix to the value or to the local part of email, the value is trimmed to the column size
+func disambiguate(value string, n int, dbType string) (string, error) {
+	suffix := "-" + strconv.Itoa(n)
+	head, tail := value, ""
+	if at := strings.LastIndex(value, "@"); at > 0 {
+		head, tail = value[:at], value[at:]
+	}
+	if strings.HasPrefix(dbType, encoding.CharType) || strings.HasPrefix(dbType, encoding.VarcharType) {
+		size, err := strconv.Atoi(strings.TrimSuffix(dbType[strings.Index(dbType, "(")+1:], ")"))
+		if err != nil {
+			return "", err
+		}
+		room := size - utf8.RuneCountInString(suffix+tail)
+		if room < 0 {
+			return "", fmt.Errorf("unique value doesn't fit type %v", dbType)
+		}
+		if runes := []rune(head); len(runes) > room {
+			head = string(runes[:room])
+		}
+	}
+	return head + suffix + tail, nil
+}
+
+func getUniqueKey(value interface{}, dbType string) uniqueKey {
+	key := toUniqueString(value)
+	if encoding.IsStringType(dbType) {
+		//case and trailing spaces are ignored by unique indexes with default collations
+		key = strings.ToLower(strings.TrimRight(key, " "))
+	}
+	var result uniqueKey
+	hash := sha256.Sum256([]byte(key))
+	copy(result[:], hash[:])
+	return result
+}
+
+func toUniqueString(value interface{}) string {
+	if pointer, ok := value.(*interface{}); ok {
+		value = *pointer
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func isNull(value interface{}) bool {
+	if pointer, ok := value.(*interface{}); ok {
+		return pointer == nil || *pointer == nil
+	}
+	return value == nil
+}
@@ -0,0 +1,127 @@
+package obfuscating
+
+import (
+	"fmt"
+	"obfuscator/encoding"
+	"testing"
+)
+
+func TestGetUniqueValues(t *testing.T) {
+	model := []Column{
+		{Name: "id", Type: encoding.IntType, IsPrimaryKey: true},
+		{Name: "email", Type: "varchar(40)", Unique: true, NeedToObfuscate: true},
+		{Name: "login", Type: "varchar(20)", Unique: true},
+		{Name: "code", Type: "varchar(20)", Unique: true, NeedToObfuscate: true, Strategy: encoding.ShuffleStrategy},
+		{Name: "name", Type: "varchar(20)", NeedToObfuscate: true},
+	}
+	unique := getUniqueValues(model)
+	if _, exists := unique["email"]; !exists || len(unique) != 1 {
+		t.Errorf("only selected UNI columns must be tracked, got %v", unique)
+	}
+}
+
+func TestUniqueValuesRetry(t *testing.T) {
+	column := Column{Name: "code", Type: encoding.IntType, Unique: true, NeedToObfuscate: true}
+	unique := getUniqueValues([]Column{column})
+	generated := []interface{}{int64(1), int64(1), int64(2)}
+	generate := func() (interface{}, error) {
+		value := generated[0]
+		generated = generated[1:]
+		return value, nil
+	}
+
+	value, err := unique.add(column, int64(1), generate)
+	if err != nil || value != int64(1) {
+		t.Fatalf("unexpected value %v, %v", value, err)
+	}
+	//the first and the second generated values collide with the inserted one
+	value, err = unique.add(column, int64(1), generate)
+	if err != nil || value != int64(2) {
+		t.Errorf("colliding value must be generated again, got %v, %v", value, err)
+	}
+	if len(generated) != 0 {
+		t.Errorf("%v values weren't generated", len(generated))
+	}
+
+	value, err = unique.add(column, nil, generate)
+	if err != nil || value != nil {
+		t.Errorf("NULL isn't unique value, got %v, %v", value, err)
+	}
+	constant := func() (interface{}, error) { return int64(2), nil }
+	if _, err = unique.add(column, int64(2), constant); err == nil {
+		t.Errorf("numeric value which always collides can't be disambiguated")
+	}
+}
+
+//string values which always collide are disambiguated by suffix
+func TestUniqueValuesSuffix(t *testing.T) {
+	column := Column{Name: "email", Type: "varchar(16)", Unique: true, NeedToObfuscate: true}
+	unique := getUniqueValues([]Column{column})
+	generateCount := 0
+	constant := func() (interface{}, error) {
+		generateCount++
+		return "user@mail.com", nil
+	}
+	var results []interface{}
+	for i := 0; i < 3; i++ {
+		value, err := unique.add(column, "user@mail.com", constant)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, value)
+	}
+	if fmt.Sprint(results) != "[user@mail.com user-2@mail.com user-3@mail.com]" {
+		t.Errorf("unexpected values %v", results)
+	}
+	if generateCount != 2*maxUniqueAttempts {
+		t.Errorf("colliding values must be generated %v times before suffix, got %v", maxUniqueAttempts, generateCount)
+	}
+
+	//unique index ignores case and trailing spaces
+	value, err := unique.add(column, "USER-2@mail.com ", constant)
+	if err != nil || value == "USER-2@mail.com " {
+		t.Errorf("value colliding by collation must be disambiguated, got %v, %v", value, err)
+	}
+}
+
+func TestDisambiguate(t *testing.T) {
+	tests := []struct {
+		value    string
+		n        int
+		dbType   string
+		expected string
+	}{
+		{"john", 2, encoding.TextType, "john-2"},
+		{"john@mail.com", 10, encoding.TextType, "john-10@mail.com"},
+		{"johnny", 2, "varchar(6)", "john-2"},
+		{"Иван", 3, "char(5)", "Ива-3"},
+	}
+	for _, test := range tests {
+		value, err := disambiguate(test.value, test.n, test.dbType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != test.expected {
+			t.Errorf("%v %v %v: got %v, expected %v", test.value, test.n, test.dbType, value, test.expected)
+		}
+	}
+	if _, err := disambiguate("a@mail.com", 2, "varchar(10)"); err == nil {
+		t.Errorf("suffix which doesn't fit the column must fail")
+	}
+}
+
+func TestNeedToObfuscateUnique(t *testing.T) {
+	if needToObfuscate(RawColumn{Field: "email", Type: "varchar(40)", Key: uniqueKeyWord}) {
+		t.Errorf("UNI column must be copied verbatim by default")
+	}
+	if !needToObfuscate(RawColumn{Field: "name", Type: "varchar(40)"}) {
+		t.Errorf("column without key must be obfuscated")
+	}
+	column := Column{Name: "email", Type: "varchar(40)"}
+	if reason := getVerbatimReason(column, uniqueKeyWord, false); reason != uniqueKeyReason {
+		t.Errorf("unexpected reason %v", reason)
+	}
+	if reason := getVerbatimReason(column, uniqueKeyWord, true); reason != foreignKeyReason {
+		t.Errorf("unexpected reason %v", reason)
+	}
+}
@@ -0,0 +1,175 @@
+package obfuscating
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type VerificationReport struct {
+	Passed      bool
+	Tables      []TableVerification
+	ForeignKeys []ForeignKeyVerification
+	Errors      []string `json:",omitempty"`
+}
+
+type TableVerification struct {
+	Table               string
+	OriginRows          int64
+	DestinationRows     int64
+	RowsMatched         bool
+	ChecksumColumns     []string
+	OriginChecksum      string
+	DestinationChecksum string
+	ChecksumMatched     bool
+}
+
+type ForeignKeyVerification struct {
+	Constraint string
+	Table      string
+	Referenced string
+	Orphans    int64
+}
+
+type foreignKey struct {
+	constraint        string
+	table             string
+	referencedTable   string
+	columns           []string
+	referencedColumns []string
+}
+
+//compares row counts and checksums of not obfuscated columns, checks foreign keys in destination
+func VerifyCopy(model map[string][]Column, originalDb, destinationDb *sql.DB, destinationSchema string) VerificationReport {
+	report := VerificationReport{Passed: true}
+
+	for _, table := range getSortedKeys(model) {
+		tableVerification, err := verifyTable(table, model[table], originalDb, destinationDb)
+		if err != nil {
+			report.Passed = false
+			report.Errors = append(report.Errors, fmt.Sprintf("table %v: %v", table, err))
+			continue
+		}
+		if !tableVerification.RowsMatched || !tableVerification.ChecksumMatched {
+			report.Passed = false
+		}
+		report.Tables = append(report.Tables, tableVerification)
+	}
+
+	foreignKeys, err := getForeignKeys(destinationDb, destinationSchema)
+	if err != nil {
+		report.Passed = false
+		report.Errors = append(report.Errors, fmt.Sprintf("foreign keys: %v", err))
+		return report
+	}
+	for _, fk := range foreignKeys {
+		orphans, err := countOrphans(destinationDb, fk)
+		if err != nil {
+			report.Passed = false
+			report.Errors = append(report.Errors, fmt.Sprintf("foreign key %v: %v", fk.constraint, err))
+			continue
+		}
+		if orphans > 0 {
+			report.Passed = false
+		}
+		report.ForeignKeys = append(report.ForeignKeys, ForeignKeyVerification{
+			Constraint: fk.constraint,
+			Table:      fk.table,
+			Referenced: fk.referencedTable,
+			Orphans:    orphans,
+		})
+	}
+	return report
+}
+
+func verifyTable(table string, columns []Column, originalDb, destinationDb *sql.DB) (TableVerification, error) {
+	result := TableVerification{Table: table}
+	for _, column := range columns {
+		if !column.NeedToObfuscate {
+			result.ChecksumColumns = append(result.ChecksumColumns, column.Name)
+		}
+	}
+
+	query := getChecksumQuery(table, result.ChecksumColumns)
+	var err error
+	result.OriginRows, result.OriginChecksum, err = getChecksum(originalDb, query)
+	if err != nil {
+		return result, err
+	}
+	result.DestinationRows, result.DestinationChecksum, err = getChecksum(destinationDb, query)
+	if err != nil {
+		return result, err
+	}
+	result.RowsMatched = result.OriginRows == result.DestinationRows
+	result.ChecksumMatched = result.OriginChecksum == result.DestinationChecksum
+	return result, nil
+}
+
+//sum of row CRCs doesn't depend on rows order, ISNULL flags distinguish NULL from skipped values
+func getChecksumQuery(table string, columns []string) string {
+	if len(columns) == 0 {
+		return fmt.Sprintf("SELECT COUNT(*), '0' FROM %v;", quoteIdentifier(table))
+	}
+	var parts []string
+	parts = append(parts, quoteIdentifiers(columns)...)
+	for _, column := range columns {
+		parts = append(parts, "ISNULL("+quoteIdentifier(column)+")")
+	}
+	return fmt.Sprintf("SELECT COUNT(*), CAST(IFNULL(SUM(CRC32(CONCAT_WS('#', %v))), 0) AS CHAR) FROM %v;",
+		strings.Join(parts, ", "), quoteIdentifier(table))
+}
+
+func getChecksum(db *sql.DB, query string) (int64, string, error) {
+	var count int64
+	var checksum string
+	err := db.QueryRow(query).Scan(&count, &checksum)
+	return count, checksum, err
+}
+
+func getForeignKeys(db *sql.DB, schemaName string) ([]foreignKey, error) {
+	rows, err := db.Query("SELECT constraint_name, table_name, column_name, referenced_table_name,"+
+		" referenced_column_name FROM information_schema.key_column_usage"+
+		" WHERE table_schema = ? AND referenced_table_name IS NOT NULL"+
+		" ORDER BY table_name, constraint_name, ordinal_position;", schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var foreignKeys []foreignKey
+	for rows.Next() {
+		var constraint, table, column, referencedTable, referencedColumn string
+		err = rows.Scan(&constraint, &table, &column, &referencedTable, &referencedColumn)
+		if err != nil {
+			return nil, err
+		}
+		last := len(foreignKeys) - 1
+		if last < 0 || foreignKeys[last].constraint != constraint || foreignKeys[last].table != table {
+			foreignKeys = append(foreignKeys, foreignKey{
+				constraint:      constraint,
+				table:           table,
+				referencedTable: referencedTable,
+			})
+			last++
+		}
+		foreignKeys[last].columns = append(foreignKeys[last].columns, column)
+		foreignKeys[last].referencedColumns = append(foreignKeys[last].referencedColumns, referencedColumn)
+	}
+	return foreignKeys, nil
+}
+
+//counts rows referencing missing rows, rows with NULL in foreign key columns aren't checked by mysql
+func countOrphans(db *sql.DB, fk foreignKey) (int64, error) {
+	var joinConditions, notNullConditions []string
+	for i, column := range fk.columns {
+		joinConditions = append(joinConditions, fmt.Sprintf("c.%v = p.%v",
+			quoteIdentifier(column), quoteIdentifier(fk.referencedColumns[i])))
+		notNullConditions = append(notNullConditions, fmt.Sprintf("c.%v IS NOT NULL", quoteIdentifier(column)))
+	}
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %v c LEFT JOIN %v p ON %v WHERE %v AND p.%v IS NULL;",
+		quoteIdentifier(fk.table), quoteIdentifier(fk.referencedTable), strings.Join(joinConditions, " AND "),
+		strings.Join(notNullConditions, " AND "), quoteIdentifier(fk.referencedColumns[0]))
+	var orphans int64
+	err := db.QueryRow(query).Scan(&orphans)
+	return orphans, err
+}
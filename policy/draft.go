@@ -0,0 +1,28 @@
+package policy
+
+import (
+	"obfuscator/encoding"
+	"obfuscator/obfuscating"
+)
+
+//drafts policy with rules for columns suggested with at least minConfidence
+func Draft(name string, suggestions map[string][]obfuscating.ColumnSuggestion, minConfidence float64) Policy {
+	p := Policy{
+		Name:   name,
+		Tables: make(map[string]TableRules),
+	}
+	for tableName, columns := range suggestions {
+		rules := make(map[string]Rule)
+		for _, column := range columns {
+			if !column.NeedToObfuscate || column.Strategy == encoding.KeepStrategy ||
+				column.Confidence < minConfidence {
+				continue
+			}
+			rules[column.Name] = Rule{Strategy: column.Strategy}
+		}
+		if len(rules) > 0 {
+			p.Tables[tableName] = TableRules{Columns: rules}
+		}
+	}
+	return p
+}
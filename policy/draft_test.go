@@ -0,0 +1,40 @@
+package policy
+
+import (
+	"obfuscator/encoding"
+	"obfuscator/obfuscating"
+	"testing"
+)
+
+func TestDraft(t *testing.T) {
+	suggestion := func(name, strategy string, needToObfuscate bool, confidence float64) obfuscating.ColumnSuggestion {
+		return obfuscating.ColumnSuggestion{
+			Column:     obfuscating.Column{Name: name, NeedToObfuscate: needToObfuscate, Strategy: strategy},
+			Confidence: confidence,
+		}
+	}
+	suggestions := map[string][]obfuscating.ColumnSuggestion{
+		"users": {
+			suggestion("email", encoding.EmailStrategy, true, 0.9),
+			suggestion("name", encoding.DefaultStrategy, true, 0.5),
+			suggestion("note", encoding.DefaultStrategy, true, 0.4),
+			suggestion("login", encoding.KeepStrategy, true, 1),
+			suggestion("id", encoding.DefaultStrategy, false, 1),
+		},
+		"logs": {suggestion("message", encoding.DefaultStrategy, true, 0.1)},
+	}
+	p := Draft("draft", suggestions, 0.5)
+	if _, exists := p.Tables["logs"]; exists || len(p.Tables) != 1 {
+		t.Errorf("table without rules must be omitted, got %v", p.Tables)
+	}
+	rules := p.Tables["users"].Columns
+	if len(rules) != 2 || rules["email"].Strategy != encoding.EmailStrategy {
+		t.Errorf("unexpected rules %v", rules)
+	}
+	if _, exists := rules["name"]; !exists {
+		t.Errorf("rule with min confidence must be drafted")
+	}
+	if err := Validate(p); err != nil {
+		t.Errorf("drafted policy is invalid: %v", err)
+	}
+}
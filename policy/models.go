@@ -0,0 +1,44 @@
+package policy
+
+import "time"
+
+type Policy struct {
+	Name     string                `yaml:"name" json:"name"`
+	Defaults Defaults              `yaml:"defaults,omitempty" json:"defaults,omitempty"`
+	Tables   map[string]TableRules `yaml:"tables,omitempty" json:"tables,omitempty"`
+}
+
+type Defaults struct {
+	//applied in order, first matched rule wins, ByName has priority over ByType
+	ByName []NameRule `yaml:"byName,omitempty" json:"byName,omitempty"`
+	ByType []TypeRule `yaml:"byType,omitempty" json:"byType,omitempty"`
+}
+
+type TableRules struct {
+	Columns map[string]Rule `yaml:"columns" json:"columns"`
+}
+
+type Rule struct {
+	Strategy string            `yaml:"strategy" json:"strategy"`
+	Params   map[string]string `yaml:"params,omitempty" json:"params,omitempty"`
+}
+
+type NameRule struct {
+	//regular expression matched against "column" and "table.column"
+	Pattern string `yaml:"pattern" json:"pattern"`
+	Rule    `yaml:",inline"`
+}
+
+type TypeRule struct {
+	//matches column type with this prefix, e.g. "varchar" matches "varchar(255)"
+	Type string `yaml:"type" json:"type"`
+	Rule `yaml:",inline"`
+}
+
+type VersionInfo struct {
+	Version int
+	Author  string
+	Comment string
+	Created time.Time
+	Changes []string
+}
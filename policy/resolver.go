@@ -0,0 +1,135 @@
+package policy
+
+import (
+	"fmt"
+	"obfuscator/encoding"
+	"obfuscator/obfuscating"
+	"regexp"
+	"strings"
+)
+
+//builds obfuscation model from policy for live schema and validates it
+func BuildModel(p Policy, dbConnInfo obfuscating.ConnectionInfo) (map[string][]obfuscating.Column, error) {
+	schema, err := obfuscating.GetSchemaInfo(dbConnInfo)
+	if err != nil {
+		return nil, err
+	}
+	model, err := Resolve(p, schema)
+	if err != nil {
+		return nil, err
+	}
+	err = obfuscating.ValidateObfuscationModel(model, dbConnInfo)
+	if err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+func Resolve(p Policy, schema map[string][]obfuscating.Column) (map[string][]obfuscating.Column, error) {
+	err := Validate(p)
+	if err != nil {
+		return nil, err
+	}
+
+	for tableName, tableRules := range p.Tables {
+		columns, contains := schema[tableName]
+		if !contains {
+			return nil, fmt.Errorf("policy %v has rules for table %v which schema hasn't", p.Name, tableName)
+		}
+		for columnName := range tableRules.Columns {
+			if !hasColumn(columns, columnName) {
+				return nil, fmt.Errorf("policy %v has rule for column %v which table %v hasn't",
+					p.Name, columnName, tableName)
+			}
+		}
+	}
+
+	nameRules := make([]*regexp.Regexp, len(p.Defaults.ByName))
+	for i, nameRule := range p.Defaults.ByName {
+		nameRules[i] = regexp.MustCompile(nameRule.Pattern) //checked in Validate
+	}
+
+	model := make(map[string][]obfuscating.Column)
+	for tableName, schemaColumns := range schema {
+		var columns []obfuscating.Column
+		for _, column := range schemaColumns {
+			rule, found := p.Tables[tableName].Columns[column.Name]
+			//explicit rules are applied as is, wrong ones are rejected by model validation
+			if !found {
+				if !column.NeedToObfuscate {
+					columns = append(columns, column)
+					continue
+				}
+				rule, found = findDefaultRule(p.Defaults, nameRules, tableName, column)
+			}
+			column.NeedToObfuscate = false
+			column.Strategy = encoding.DefaultStrategy
+			column.Params = nil
+			if found && rule.Strategy != encoding.KeepStrategy {
+				column.NeedToObfuscate = true
+				column.Strategy = rule.Strategy
+				column.Params = rule.Params
+			}
+			columns = append(columns, column)
+		}
+		model[tableName] = columns
+	}
+	return model, nil
+}
+
+func Validate(p Policy) error {
+	if !isValidName(p.Name) {
+		return fmt.Errorf("invalid policy name %q, only letters, digits, '-' and '_' are allowed", p.Name)
+	}
+	for _, nameRule := range p.Defaults.ByName {
+		if _, err := regexp.Compile(nameRule.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q in policy %v: %v", nameRule.Pattern, p.Name, err)
+		}
+		if err := validateRule(nameRule.Rule); err != nil {
+			return fmt.Errorf("pattern %q in policy %v: %v", nameRule.Pattern, p.Name, err)
+		}
+	}
+	for _, typeRule := range p.Defaults.ByType {
+		if err := validateRule(typeRule.Rule); err != nil {
+			return fmt.Errorf("type %v in policy %v: %v", typeRule.Type, p.Name, err)
+		}
+	}
+	for tableName, tableRules := range p.Tables {
+		for columnName, rule := range tableRules.Columns {
+			if err := validateRule(rule); err != nil {
+				return fmt.Errorf("column %v.%v in policy %v: %v", tableName, columnName, p.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateRule(rule Rule) error {
+	if !encoding.IsKnownStrategy(rule.Strategy) {
+		return fmt.Errorf("unknown strategy %q", rule.Strategy)
+	}
+	return nil
+}
+
+func findDefaultRule(defaults Defaults, nameRules []*regexp.Regexp, tableName string, column obfuscating.Column) (Rule, bool) {
+	for i, nameRule := range nameRules {
+		if nameRule.MatchString(column.Name) || nameRule.MatchString(tableName+"."+column.Name) {
+			return defaults.ByName[i].Rule, true
+		}
+	}
+	for _, typeRule := range defaults.ByType {
+		if strings.HasPrefix(column.Type, typeRule.Type) {
+			return typeRule.Rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+func hasColumn(columns []obfuscating.Column, name string) bool {
+	for _, column := range columns {
+		if column.Name == name {
+			return true
+		}
+	}
+	return false
+}
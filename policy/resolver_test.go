@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"obfuscator/encoding"
+	"obfuscator/obfuscating"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	p := Policy{
+		Name: "users",
+		Defaults: Defaults{
+			ByName: []NameRule{
+				{Pattern: "^users\\.login$", Rule: Rule{Strategy: encoding.KeepStrategy}},
+				{Pattern: "mail", Rule: Rule{Strategy: encoding.EmailStrategy}},
+				{Pattern: "^e", Rule: Rule{Strategy: encoding.HashStrategy}},
+			},
+			ByType: []TypeRule{
+				{Type: "varchar", Rule: Rule{Strategy: encoding.MaskStrategy}},
+				{Type: "varchar(20)", Rule: Rule{Strategy: encoding.HashStrategy}},
+			},
+		},
+		Tables: map[string]TableRules{
+			"users": {Columns: map[string]Rule{
+				"phone": {Strategy: encoding.MaskStrategy, Params: map[string]string{"keepLast": "4"}},
+				"note":  {Strategy: encoding.KeepStrategy},
+				"id":    {Strategy: encoding.HashStrategy},
+			}},
+		},
+	}
+	schema := map[string][]obfuscating.Column{
+		"users": {
+			{Name: "id", Type: encoding.IntType, IsPrimaryKey: true},
+			{Name: "email", Type: "varchar(40)", NeedToObfuscate: true},
+			{Name: "login", Type: "varchar(20)", NeedToObfuscate: true},
+			{Name: "name", Type: "varchar(20)", NeedToObfuscate: true},
+			{Name: "phone", Type: "varchar(20)", NeedToObfuscate: true},
+			{Name: "note", Type: encoding.TextType, NeedToObfuscate: true},
+			{Name: "age", Type: encoding.IntType, NeedToObfuscate: true, Strategy: encoding.RoundStrategy},
+			{Name: "code", Type: "varchar(20)"},
+		},
+	}
+	model, err := Resolve(p, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		column          string
+		needToObfuscate bool
+		strategy        string
+		params          int
+	}{
+		{"id", true, encoding.HashStrategy, 0},        //explicit rule is applied to column copied by default
+		{"email", true, encoding.EmailStrategy, 0},    //first matched pattern wins
+		{"login", false, encoding.DefaultStrategy, 0}, //pattern matches table.column
+		{"name", true, encoding.MaskStrategy, 0},      //first matched type prefix wins
+		{"phone", true, encoding.MaskStrategy, 1},     //explicit rule has priority over defaults
+		{"note", false, encoding.DefaultStrategy, 0},  //explicit keep
+		{"age", false, encoding.DefaultStrategy, 0},   //unmatched column isn't obfuscated
+		{"code", false, encoding.DefaultStrategy, 0},  //column copied by schema isn't matched by defaults
+	}
+	columns := make(map[string]obfuscating.Column)
+	for _, column := range model["users"] {
+		columns[column.Name] = column
+	}
+	for _, test := range tests {
+		column := columns[test.column]
+		if column.NeedToObfuscate != test.needToObfuscate || column.Strategy != test.strategy ||
+			len(column.Params) != test.params {
+			t.Errorf("%v: got %v %q %v, expected %v %q %v params", test.column, column.NeedToObfuscate,
+				column.Strategy, column.Params, test.needToObfuscate, test.strategy, test.params)
+		}
+	}
+}
+
+func TestResolveUnknownNames(t *testing.T) {
+	schema := map[string][]obfuscating.Column{"users": {{Name: "email", Type: "varchar(40)"}}}
+	tests := []Policy{
+		{Name: "users", Tables: map[string]TableRules{"orders": {Columns: map[string]Rule{"email": {}}}}},
+		{Name: "users", Tables: map[string]TableRules{"users": {Columns: map[string]Rule{"phone": {}}}}},
+	}
+	for _, p := range tests {
+		if _, err := Resolve(p, schema); err == nil {
+			t.Errorf("rules of %v must be rejected", p.Tables)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		p     Policy
+		valid bool
+	}{
+		{Policy{Name: "users-2_a"}, true},
+		{Policy{Name: ""}, false},
+		{Policy{Name: "users.yaml"}, false},
+		{Policy{Name: "users", Defaults: Defaults{ByName: []NameRule{{Pattern: "(mail"}}}}, false},
+		{Policy{Name: "users", Defaults: Defaults{ByName: []NameRule{{Pattern: "mail",
+			Rule: Rule{Strategy: "unknown"}}}}}, false},
+		{Policy{Name: "users", Defaults: Defaults{ByType: []TypeRule{{Type: "int",
+			Rule: Rule{Strategy: "unknown"}}}}}, false},
+		{Policy{Name: "users", Tables: map[string]TableRules{"users": {Columns: map[string]Rule{
+			"email": {Strategy: "unknown"}}}}}, false},
+	}
+	for i, test := range tests {
+		if err := Validate(test.p); (err == nil) != test.valid {
+			t.Errorf("test %v: unexpected result %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"obfuscator/config"
+	"obfuscator/encoding"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	policiesDirName = "policies"
+	historyFileName = "history.json"
+	versionFileExt  = ".yaml"
+)
+
+var (
+	namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	storeMutex  sync.Mutex
+)
+
+func Parse(data []byte) (Policy, error) {
+	var p Policy
+	//JSON is a subset of YAML, so both formats are accepted
+	err := yaml.UnmarshalStrict(data, &p)
+	return p, err
+}
+
+func ParseFile(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, err
+	}
+	return Parse(data)
+}
+
+func Save(p Policy, author, comment string) (VersionInfo, error) {
+	err := Validate(p)
+	if err != nil {
+		return VersionInfo{}, err
+	}
+
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+
+	history, err := readHistory(p.Name)
+	if err != nil {
+		return VersionInfo{}, err
+	}
+
+	var previous Policy
+	if len(history) > 0 {
+		previous, err = readVersion(p.Name, history[len(history)-1].Version)
+		if err != nil {
+			return VersionInfo{}, err
+		}
+	}
+
+	info := VersionInfo{
+		Version: len(history) + 1,
+		Author:  author,
+		Comment: comment,
+		Created: time.Now().UTC(),
+		Changes: diff(previous, p),
+	}
+
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return VersionInfo{}, err
+	}
+	dir := getPolicyDir(p.Name)
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return VersionInfo{}, err
+	}
+	err = os.WriteFile(filepath.Join(dir, strconv.Itoa(info.Version)+versionFileExt), data, 0600)
+	if err != nil {
+		return VersionInfo{}, err
+	}
+
+	history = append(history, info)
+	data, err = json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return VersionInfo{}, err
+	}
+	err = os.WriteFile(filepath.Join(dir, historyFileName), data, 0600)
+	if err != nil {
+		return VersionInfo{}, err
+	}
+	return info, nil
+}
+
+//returns latest version if version is 0
+func Load(name string, version int) (Policy, int, error) {
+	if !isValidName(name) {
+		return Policy{}, 0, fmt.Errorf("invalid policy name %q", name)
+	}
+
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+
+	history, err := readHistory(name)
+	if err != nil {
+		return Policy{}, 0, err
+	}
+	if len(history) == 0 {
+		return Policy{}, 0, fmt.Errorf("policy %v doesn't exist", name)
+	}
+	if version == 0 {
+		version = history[len(history)-1].Version
+	}
+	if version < 0 || version > len(history) {
+		return Policy{}, 0, fmt.Errorf("policy %v hasn't version %v", name, version)
+	}
+	p, err := readVersion(name, version)
+	return p, version, err
+}
+
+func History(name string) ([]VersionInfo, error) {
+	if !isValidName(name) {
+		return nil, fmt.Errorf("invalid policy name %q", name)
+	}
+
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+
+	history, err := readHistory(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("policy %v doesn't exist", name)
+	}
+	return history, nil
+}
+
+func List() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(config.GetConfig().Storage.Dir, policiesDirName))
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func readHistory(name string) ([]VersionInfo, error) {
+	data, err := os.ReadFile(filepath.Join(getPolicyDir(name), historyFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var history []VersionInfo
+	err = json.Unmarshal(data, &history)
+	return history, err
+}
+
+func readVersion(name string, version int) (Policy, error) {
+	return ParseFile(filepath.Join(getPolicyDir(name), strconv.Itoa(version)+versionFileExt))
+}
+
+func getPolicyDir(name string) string {
+	return filepath.Join(config.GetConfig().Storage.Dir, policiesDirName, name)
+}
+
+func isValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+//returns human-readable list of changed rules, e.g. "users.email: keep -> hash"
+func diff(previous, current Policy) []string {
+	oldRules := flattenRules(previous)
+	newRules := flattenRules(current)
+
+	var changes []string
+	for key, newRule := range newRules {
+		oldRule, existed := oldRules[key]
+		if !existed {
+			changes = append(changes, fmt.Sprintf("%v: added %v", key, newRule))
+		} else if oldRule != newRule {
+			changes = append(changes, fmt.Sprintf("%v: %v -> %v", key, oldRule, newRule))
+		}
+	}
+	for key, oldRule := range oldRules {
+		if _, exists := newRules[key]; !exists {
+			changes = append(changes, fmt.Sprintf("%v: removed %v", key, oldRule))
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+func flattenRules(p Policy) map[string]string {
+	result := make(map[string]string)
+	for i, nameRule := range p.Defaults.ByName {
+		result[fmt.Sprintf("defaults.byName[%v] %q", i, nameRule.Pattern)] = ruleToString(nameRule.Rule)
+	}
+	for i, typeRule := range p.Defaults.ByType {
+		result[fmt.Sprintf("defaults.byType[%v] %q", i, typeRule.Type)] = ruleToString(typeRule.Rule)
+	}
+	for tableName, tableRules := range p.Tables {
+		for columnName, rule := range tableRules.Columns {
+			result[tableName+"."+columnName] = ruleToString(rule)
+		}
+	}
+	return result
+}
+
+func ruleToString(rule Rule) string {
+	strategy := rule.Strategy
+	if strategy == encoding.DefaultStrategy {
+		strategy = "default"
+	}
+	if len(rule.Params) == 0 {
+		return strategy
+	}
+	var params []string
+	for key, value := range rule.Params {
+		params = append(params, key+"="+value)
+	}
+	sort.Strings(params)
+	return strategy + "(" + strings.Join(params, ", ") + ")"
+}
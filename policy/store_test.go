@@ -0,0 +1,146 @@
+package policy
+
+import (
+	"fmt"
+	"obfuscator/config"
+	"obfuscator/encoding"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	//config is found from package directory, storage dir is relative to the temporary working directory
+	config.GetConfig()
+	dir, err := os.MkdirTemp("", "policies")
+	if err != nil {
+		panic(err)
+	}
+	err = os.Chdir(dir)
+	if err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestSaveVersions(t *testing.T) {
+	first := Policy{Name: "versions", Tables: map[string]TableRules{
+		"users": {Columns: map[string]Rule{"email": {Strategy: encoding.HashStrategy}}},
+	}}
+	second := Policy{Name: "versions", Tables: map[string]TableRules{
+		"users": {Columns: map[string]Rule{"email": {Strategy: encoding.KeepStrategy}}},
+	}}
+	for i, p := range []Policy{first, second} {
+		info, err := Save(p, "author", fmt.Sprintf("comment %v", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Version != i+1 {
+			t.Errorf("got version %v, expected %v", info.Version, i+1)
+		}
+	}
+
+	tests := []struct {
+		version  int
+		expected int
+		strategy string
+	}{
+		{0, 2, encoding.KeepStrategy},
+		{1, 1, encoding.HashStrategy},
+		{2, 2, encoding.KeepStrategy},
+	}
+	for _, test := range tests {
+		p, version, err := Load("versions", test.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strategy := p.Tables["users"].Columns["email"].Strategy; version != test.expected || strategy != test.strategy {
+			t.Errorf("version %v: got %v %v, expected %v %v", test.version, version, strategy, test.expected,
+				test.strategy)
+		}
+	}
+	for _, version := range []int{-1, 3} {
+		if _, _, err := Load("versions", version); err == nil {
+			t.Errorf("version %v doesn't exist", version)
+		}
+	}
+
+	history, err := History("versions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[1].Author != "author" || history[1].Comment != "comment 1" ||
+		fmt.Sprint(history[1].Changes) != "[users.email: hash -> keep]" {
+		t.Errorf("unexpected history %+v", history)
+	}
+	names, err := List()
+	if err != nil || fmt.Sprint(names) != "[versions]" {
+		t.Errorf("unexpected policies %v, %v", names, err)
+	}
+}
+
+func TestStoreInvalidNames(t *testing.T) {
+	if _, err := Save(Policy{Name: "../users"}, "author", ""); err == nil {
+		t.Errorf("policy with invalid name must be rejected")
+	}
+	if _, _, err := Load("../users", 0); err == nil {
+		t.Errorf("invalid name must be rejected")
+	}
+	if _, _, err := Load("missing", 0); err == nil {
+		t.Errorf("missing policy must fail")
+	}
+	if _, err := History("missing"); err == nil {
+		t.Errorf("missing policy must fail")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	hash := Rule{Strategy: encoding.HashStrategy}
+	tests := []struct {
+		previous Policy
+		current  Policy
+		expected string
+	}{
+		{Policy{}, Policy{}, "[]"},
+		{Policy{}, Policy{Tables: map[string]TableRules{"users": {Columns: map[string]Rule{"email": hash}}}},
+			"[users.email: added hash]"},
+		{Policy{Tables: map[string]TableRules{"users": {Columns: map[string]Rule{"email": hash}}}}, Policy{},
+			"[users.email: removed hash]"},
+		{Policy{Tables: map[string]TableRules{"users": {Columns: map[string]Rule{"email": hash}}}},
+			Policy{Tables: map[string]TableRules{"users": {Columns: map[string]Rule{"email": hash}}}}, "[]"},
+		{Policy{Defaults: Defaults{ByType: []TypeRule{{Type: "varchar", Rule: Rule{Strategy: encoding.DefaultStrategy}}}}},
+			Policy{Defaults: Defaults{ByType: []TypeRule{{Type: "varchar",
+				Rule: Rule{Strategy: encoding.MaskStrategy, Params: map[string]string{"keepLast": "4", "char": "#"}}}}}},
+			`[defaults.byType[0] "varchar": default -> mask(char=#, keepLast=4)]`},
+		{Policy{Defaults: Defaults{ByName: []NameRule{{Pattern: "mail", Rule: hash}}}},
+			Policy{Tables: map[string]TableRules{"users": {Columns: map[string]Rule{"email": hash}}}},
+			`[defaults.byName[0] "mail": removed hash users.email: added hash]`},
+	}
+	for i, test := range tests {
+		if changes := fmt.Sprint(diff(test.previous, test.current)); changes != test.expected {
+			t.Errorf("test %v: got %v, expected %v", i, changes, test.expected)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		data  string
+		valid bool
+	}{
+		{"name: users\ntables:\n  users:\n    columns:\n      email: {strategy: hash}\n", true},
+		{`{"name": "users", "defaults": {"byType": [{"type": "varchar", "strategy": "mask"}]}}`, true},
+		{"name: users\ncolumns: {}\n", false},
+		{"name: [users]\n", false},
+	}
+	for _, test := range tests {
+		p, err := Parse([]byte(test.data))
+		if (err == nil) != test.valid {
+			t.Errorf("%q: unexpected result %v", test.data, err)
+		}
+		if err == nil && p.Name != "users" {
+			t.Errorf("%q: unexpected name %v", test.data, p.Name)
+		}
+	}
+}
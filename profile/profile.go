@@ -0,0 +1,176 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"obfuscator/config"
+	"obfuscator/obfuscating"
+	"obfuscator/secrets"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+const (
+	profilesFileName = "profiles.json"
+)
+
+var (
+	namePattern   = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	profilesMutex sync.Mutex
+)
+
+type storedProfile struct {
+	obfuscating.ConnectionInfo
+	EncryptedPassword string
+}
+
+func Save(name string, connInfo obfuscating.ConnectionInfo) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q, only letters, digits, '-' and '_' are allowed", name)
+	}
+	connInfo.Profile = ""
+	//schema of profile is the default one, requests can set another
+	err := obfuscating.ValidateServerInfo(connInfo)
+	if err != nil {
+		return err
+	}
+	encrypted, err := secrets.Encrypt([]byte(connInfo.Password))
+	if err != nil {
+		return err
+	}
+	connInfo.Password = ""
+
+	profilesMutex.Lock()
+	defer profilesMutex.Unlock()
+	profiles, err := readProfiles()
+	if err != nil {
+		return err
+	}
+	profiles[name] = storedProfile{
+		ConnectionInfo:    connInfo,
+		EncryptedPassword: encrypted,
+	}
+	return writeProfiles(profiles)
+}
+
+func Delete(name string) error {
+	profilesMutex.Lock()
+	defer profilesMutex.Unlock()
+	profiles, err := readProfiles()
+	if err != nil {
+		return err
+	}
+	if _, exists := profiles[name]; !exists {
+		return fmt.Errorf("profile %v doesn't exist", name)
+	}
+	delete(profiles, name)
+	return writeProfiles(profiles)
+}
+
+//passwords aren't returned
+func List() ([]obfuscating.ConnectionInfo, error) {
+	profilesMutex.Lock()
+	defer profilesMutex.Unlock()
+	profiles, err := readProfiles()
+	if err != nil {
+		return nil, err
+	}
+	result := []obfuscating.ConnectionInfo{}
+	for name, stored := range profiles {
+		connInfo := stored.ConnectionInfo
+		connInfo.Profile = name
+		result = append(result, connInfo)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Profile < result[j].Profile
+	})
+	return result, nil
+}
+
+//replaces connection info with profile if it's set and validates result,
+//the request can set only schema and timeouts not to send credentials of the profile to another server
+func Resolve(connInfo *obfuscating.ConnectionInfo) error {
+	if connInfo.Profile == "" {
+		return obfuscating.ValidateConnectionInfo(*connInfo)
+	}
+
+	profilesMutex.Lock()
+	defer profilesMutex.Unlock()
+	profiles, err := readProfiles()
+	if err != nil {
+		return err
+	}
+	stored, exists := profiles[connInfo.Profile]
+	if !exists {
+		return fmt.Errorf("profile %v doesn't exist", connInfo.Profile)
+	}
+	password, err := secrets.Decrypt(stored.EncryptedPassword)
+	if err != nil {
+		return fmt.Errorf("can't decrypt password of profile %v: %v", connInfo.Profile, err)
+	}
+	stored.Password = string(password)
+	err = mergeConnectionInfo(connInfo, stored.ConnectionInfo)
+	if err != nil {
+		return err
+	}
+	return obfuscating.ValidateConnectionInfo(*connInfo)
+}
+
+func mergeConnectionInfo(connInfo *obfuscating.ConnectionInfo, stored obfuscating.ConnectionInfo) error {
+	if connInfo.User != "" || connInfo.Password != "" || connInfo.Host != "" || connInfo.Socket != "" ||
+		connInfo.TlsMode != "" || connInfo.TlsCa != "" || connInfo.TlsCert != "" || connInfo.TlsKey != "" ||
+		connInfo.TlsServerName != "" || connInfo.Charset != "" || connInfo.Collation != "" ||
+		len(connInfo.Params) > 0 {
+		return fmt.Errorf("only Schema and timeouts can be set with profile %v", connInfo.Profile)
+	}
+	fields := []struct {
+		value  string
+		stored *string
+	}{
+		{connInfo.Schema, &stored.Schema},
+		{connInfo.ConnectTimeout, &stored.ConnectTimeout},
+		{connInfo.ReadTimeout, &stored.ReadTimeout},
+		{connInfo.WriteTimeout, &stored.WriteTimeout},
+	}
+	for _, field := range fields {
+		if field.value != "" {
+			*field.stored = field.value
+		}
+	}
+	stored.Profile = connInfo.Profile
+	*connInfo = stored
+	return nil
+}
+
+func readProfiles() (map[string]storedProfile, error) {
+	profiles := make(map[string]storedProfile)
+	data, err := os.ReadFile(getProfilesFilePath())
+	if os.IsNotExist(err) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &profiles)
+	return profiles, err
+}
+
+func writeProfiles(profiles map[string]storedProfile) error {
+	data, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := getProfilesFilePath()
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func getProfilesFilePath() string {
+	return filepath.Join(config.GetConfig().Storage.Dir, profilesFileName)
+}
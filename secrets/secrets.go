@@ -0,0 +1,187 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"obfuscator/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	masterKeyEnv = "OBFUSCATOR_MASTER_KEY" //hex encoded
+	fpeKeyEnv    = "OBFUSCATOR_FPE_KEY"    //hex encoded
+	keySize      = 32
+)
+
+var (
+	masterKey      []byte
+	masterKeyMutex sync.Mutex
+	fpeKey         []byte
+	fpeKeyMutex    sync.Mutex
+)
+
+//encrypts with AES-256-GCM using master key, result is base64 of nonce and ciphertext
+func Encrypt(plaintext []byte) (string, error) {
+	aead, err := getCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func Decrypt(encrypted string) ([]byte, error) {
+	aead, err := getCipher()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted value is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+//master key is taken from environment or key file created by InitKeys
+func GetMasterKey() ([]byte, error) {
+	masterKeyMutex.Lock()
+	defer masterKeyMutex.Unlock()
+	if masterKey == nil {
+		key, err := loadKey(masterKeyEnv, config.GetConfig().Secrets.MasterKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		masterKey = key
+	}
+	return masterKey, nil
+}
+
+//key of format-preserving encryption is kept apart from master key,
+//so obfuscated values can be re-identified only where this key is available
+func GetFpeKey() ([]byte, error) {
+	fpeKeyMutex.Lock()
+	defer fpeKeyMutex.Unlock()
+	if fpeKey == nil {
+		key, err := loadKey(fpeKeyEnv, config.GetConfig().Secrets.FpeKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		fpeKey = key
+	}
+	return fpeKey, nil
+}
+
+//generates key files which don't exist and whose keys aren't set in environment, returns paths of generated files.
+//Keys aren't generated on use, otherwise a missing file would silently replace the key of existing ciphertexts
+func InitKeys() ([]string, error) {
+	keys := []struct {
+		env  string
+		path string
+	}{
+		{masterKeyEnv, config.GetConfig().Secrets.MasterKeyFile},
+		{fpeKeyEnv, config.GetConfig().Secrets.FpeKeyFile},
+	}
+	var generated []string
+	for _, key := range keys {
+		if os.Getenv(key.env) != "" {
+			continue
+		}
+		_, err := os.Stat(key.path)
+		if err == nil {
+			continue
+		}
+		if !os.IsNotExist(err) {
+			return generated, err
+		}
+		err = generateKey(key.path)
+		if err != nil {
+			return generated, err
+		}
+		generated = append(generated, key.path)
+	}
+	return generated, nil
+}
+
+func loadKey(env, path string) ([]byte, error) {
+	if value := os.Getenv(env); value != "" {
+		key, err := decodeKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", env, err)
+		}
+		return key, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("key file %v doesn't exist, set %v or run \"obfuscator init-keys\"", path, env)
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, err := decodeKey(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+	return key, nil
+}
+
+func generateKey(path string) error {
+	key := make([]byte, keySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	//the file isn't overwritten if it's created concurrently
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0400)
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(hex.EncodeToString(key))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func getCipher() (cipher.AEAD, error) {
+	key, err := GetMasterKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func decodeKey(value string) ([]byte, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key must be %v bytes", keySize)
+	}
+	return key, nil
+}
@@ -0,0 +1,202 @@
+package vault
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"obfuscator/config"
+	"obfuscator/secrets"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	//bucket of domain has the same name, reverse index has the suffix
+	pseudonymsSuffix = ":pseudonyms"
+	//attempts to generate pseudonym which isn't used for another value
+	maxAttempts = 10
+	openTimeout = 10 * time.Second
+)
+
+var (
+	db      *bolt.DB
+	dbMutex sync.Mutex
+)
+
+var (
+	//domain -> index key of value -> new mapping which isn't written to the vault yet
+	pending = make(map[string]map[string]mapping)
+	//domain -> index keys of pending pseudonyms
+	pendingPseudonyms = make(map[string]map[string]bool)
+	pendingMutex      sync.Mutex
+)
+
+//stored encrypted, keys are HMACs of values, so the vault file doesn't contain original values in plain text
+type mapping struct {
+	Original  string
+	Pseudonym string
+}
+
+//returns pseudonym of the value in the domain, the new one is generated and kept in memory until Flush.
+//Pseudonyms are unique in the domain, so joins by pseudonyms work as by original values.
+//Number of the attempt is passed to generate, so deterministic generators can disambiguate colliding pseudonyms
+func GetOrCreate(domain, original string, generate func(attempt int) (string, error)) (string, error) {
+	if domain == "" {
+		return "", fmt.Errorf("domain is required")
+	}
+	vaultDb, err := getDb()
+	if err != nil {
+		return "", err
+	}
+	key, err := getIndexKey(domain, original)
+	if err != nil {
+		return "", err
+	}
+
+	pendingMutex.Lock()
+	defer pendingMutex.Unlock()
+	if newMapping, exists := pending[domain][string(key)]; exists {
+		return newMapping.Pseudonym, nil
+	}
+
+	var pseudonym string
+	err = vaultDb.View(func(tx *bolt.Tx) error {
+		pseudonym, err = readPseudonym(tx, domain, key)
+		if err != nil || pseudonym != "" {
+			return err
+		}
+		pseudonyms := tx.Bucket([]byte(domain + pseudonymsSuffix))
+		for attempt := 0; attempt < maxAttempts; attempt++ {
+			candidate, err := generate(attempt)
+			if err != nil {
+				return err
+			}
+			pseudonymKey, err := getIndexKey(domain, candidate)
+			if err != nil {
+				return err
+			}
+			if pendingPseudonyms[domain][string(pseudonymKey)] ||
+				pseudonyms != nil && pseudonyms.Get(pseudonymKey) != nil {
+				continue
+			}
+			if pending[domain] == nil {
+				pending[domain] = make(map[string]mapping)
+				pendingPseudonyms[domain] = make(map[string]bool)
+			}
+			pending[domain][string(key)] = mapping{Original: original, Pseudonym: candidate}
+			pendingPseudonyms[domain][string(pseudonymKey)] = true
+			pseudonym = candidate
+			return nil
+		}
+		return fmt.Errorf("can't generate unique pseudonym in domain %v", domain)
+	})
+	return pseudonym, err
+}
+
+//writes new pseudonyms to the vault in one transaction, it must be done before they're written to destination
+func Flush() error {
+	pendingMutex.Lock()
+	defer pendingMutex.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+	vaultDb, err := getDb()
+	if err != nil {
+		return err
+	}
+	err = vaultDb.Update(func(tx *bolt.Tx) error {
+		for domain, domainMappings := range pending {
+			mappings, err := tx.CreateBucketIfNotExists([]byte(domain))
+			if err != nil {
+				return err
+			}
+			pseudonyms, err := tx.CreateBucketIfNotExists([]byte(domain + pseudonymsSuffix))
+			if err != nil {
+				return err
+			}
+			for key, newMapping := range domainMappings {
+				pseudonymKey, err := getIndexKey(domain, newMapping.Pseudonym)
+				if err != nil {
+					return err
+				}
+				data, err := json.Marshal(newMapping)
+				if err != nil {
+					return err
+				}
+				encrypted, err := secrets.Encrypt(data)
+				if err != nil {
+					return err
+				}
+				err = mappings.Put([]byte(key), []byte(encrypted))
+				if err != nil {
+					return err
+				}
+				err = pseudonyms.Put(pseudonymKey, []byte(key))
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	pending = make(map[string]map[string]mapping)
+	pendingPseudonyms = make(map[string]map[string]bool)
+	return nil
+}
+
+func readPseudonym(tx *bolt.Tx, domain string, key []byte) (string, error) {
+	mappings := tx.Bucket([]byte(domain))
+	if mappings == nil {
+		return "", nil
+	}
+	encrypted := mappings.Get(key)
+	if encrypted == nil {
+		return "", nil
+	}
+	data, err := secrets.Decrypt(string(encrypted))
+	if err != nil {
+		return "", err
+	}
+	var result mapping
+	err = json.Unmarshal(data, &result)
+	return result.Pseudonym, err
+}
+
+func getIndexKey(domain, value string) ([]byte, error) {
+	masterKey, err := secrets.GetMasterKey()
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, masterKey)
+	mac.Write([]byte(domain))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil), nil
+}
+
+//vault is opened on first use and kept open, the file is locked by the process meanwhile
+func getDb() (*bolt.DB, error) {
+	dbMutex.Lock()
+	defer dbMutex.Unlock()
+	if db != nil {
+		return db, nil
+	}
+	path := config.GetConfig().Vault.File
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return nil, err
+	}
+	db, err = bolt.Open(path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		db = nil
+		return nil, fmt.Errorf("can't open vault %v: %v", path, err)
+	}
+	return db, nil
+}
@@ -0,0 +1,120 @@
+package vault
+
+import (
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	//key files aren't used by tests
+	os.Setenv("OBFUSCATOR_MASTER_KEY", "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	os.Exit(m.Run())
+}
+
+func openTestDb(t *testing.T) {
+	var err error
+	db, err = bolt.Open(filepath.Join(t.TempDir(), "vault.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		db = nil
+		pending = make(map[string]map[string]mapping)
+		pendingPseudonyms = make(map[string]map[string]bool)
+	})
+}
+
+func countMappings(t *testing.T, domain string) int {
+	count := 0
+	err := db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket([]byte(domain)); bucket != nil {
+			count = bucket.Stats().KeyN
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestGetOrCreate(t *testing.T) {
+	openTestDb(t)
+	generated := 0
+	generate := func(_ int) (string, error) {
+		generated++
+		return fmt.Sprintf("pseudonym-%v", generated), nil
+	}
+	first, err := GetOrCreate("customer", "john", generate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := GetOrCreate("customer", "john", generate)
+	if err != nil || again != first {
+		t.Errorf("pending pseudonym must be reused, got %v and %v, %v", first, again, err)
+	}
+	if countMappings(t, "customer") != 0 {
+		t.Errorf("pseudonyms must be kept in memory until flush")
+	}
+
+	_, err = GetOrCreate("customer", "jane", generate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if count := countMappings(t, "customer"); count != 2 || len(pending) != 0 {
+		t.Errorf("flush wrote %v mappings, %v domains are pending", count, len(pending))
+	}
+	again, err = GetOrCreate("customer", "john", generate)
+	if err != nil || again != first {
+		t.Errorf("stored pseudonym must be reused, got %v and %v, %v", first, again, err)
+	}
+	other, err := GetOrCreate("supplier", "john", generate)
+	if err != nil || other == first {
+		t.Errorf("domains must have separate pseudonyms, got %v, %v", other, err)
+	}
+	if _, err = GetOrCreate("", "john", generate); err == nil {
+		t.Errorf("empty domain must be rejected")
+	}
+}
+
+//colliding pseudonyms of pending and stored mappings are generated again with the next attempt
+func TestGetOrCreateCollisions(t *testing.T) {
+	openTestDb(t)
+	var attempts []int
+	generate := func(attempt int) (string, error) {
+		attempts = append(attempts, attempt)
+		return fmt.Sprintf("pseudonym-%v", attempt), nil
+	}
+	for i, original := range []string{"a", "b"} {
+		pseudonym, err := GetOrCreate("domain", original, generate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pseudonym != fmt.Sprintf("pseudonym-%v", i) {
+			t.Errorf("%v: unexpected pseudonym %v", original, pseudonym)
+		}
+	}
+	if err := Flush(); err != nil {
+		t.Fatal(err)
+	}
+	attempts = nil
+	pseudonym, err := GetOrCreate("domain", "c", generate)
+	if err != nil || pseudonym != "pseudonym-2" {
+		t.Errorf("unexpected pseudonym %v, %v", pseudonym, err)
+	}
+	if fmt.Sprint(attempts) != "[0 1 2]" {
+		t.Errorf("unexpected attempts %v", attempts)
+	}
+
+	constant := func(int) (string, error) { return "pseudonym-0", nil }
+	if _, err = GetOrCreate("domain", "d", constant); err == nil {
+		t.Errorf("generator which always collides must fail")
+	}
+}